@@ -44,6 +44,7 @@ async fn send_tweet(tweet: web::Json<Tweet>, data: web::Data<AppState>) -> impl
         temperature: tweet.temperature,
         humidity: tweet.humidity,
         weather: map_weather(&tweet.weather),
+        ..Default::default()
     });
 
     let mut client = data.grpc_client.clone();
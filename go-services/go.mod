@@ -5,8 +5,11 @@ go 1.24.0
 toolchain go1.24.9
 
 require (
+	connectrpc.com/connect v1.18.1
+	connectrpc.com/cors v0.1.0
 	github.com/confluentinc/confluent-kafka-go/v2 v2.12.0
 	github.com/go-redis/redis/v8 v8.11.5
+	github.com/gorilla/websocket v1.5.0
 	github.com/streadway/amqp v1.1.0
 	google.golang.org/grpc v1.76.0
 	google.golang.org/protobuf v1.36.10
@@ -0,0 +1,255 @@
+// Command grafana_api serves the Valkey aggregates over the Grafana JSON
+// datasource protocol, so dashboards can chart them without a Redis plugin.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-services/storage"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// maxRange bounds a query to the default series retention. Series and
+// annotations are read at a resolution coarse enough for
+// storage.MaxSeriesBuckets.
+const maxRange = 30 * 24 * time.Hour
+
+type server struct {
+	rdb *redis.Client
+}
+
+// timeRange is the dashboard time range of a request.
+type timeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type adhocFilter struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+type queryTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	Type   string `json:"type"`
+}
+
+type queryRequest struct {
+	Range        timeRange     `json:"range"`
+	IntervalMs   int64         `json:"intervalMs"`
+	Targets      []queryTarget `json:"targets"`
+	AdhocFilters []adhocFilter `json:"adhocFilters"`
+}
+
+type timeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+type tableColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type table struct {
+	Type    string          `json:"type"`
+	Columns []tableColumn   `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+type annotationRequest struct {
+	Range      timeRange `json:"range"`
+	Annotation struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	} `json:"annotation"`
+}
+
+type annotation struct {
+	Time  int64    `json:"time"`
+	Title string   `json:"title"`
+	Text  string   `json:"text"`
+	Tags  []string `json:"tags"`
+}
+
+type tagKey struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type tagValue struct {
+	Text string `json:"text"`
+}
+
+// sourceTag is the ad hoc filter choosing the view counts are read from.
+const sourceTag = "source"
+
+var sources = []string{storage.SourceCombined, storage.SourceKafka, storage.SourceRabbitMQ}
+
+// handleTest answers the datasource connection test.
+func (s *server) handleTest(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if err := s.rdb.Ping(r.Context()).Err(); err != nil {
+		http.Error(w, "valkey: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Target string `json:"target"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	var names []string
+	for _, name := range targetNames() {
+		if strings.Contains(name, req.Target) {
+			names = append(names, name)
+		}
+	}
+	writeJSON(w, names)
+}
+
+func (s *server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Range.To.Sub(req.Range.From) > maxRange {
+		http.Error(w, "time range is longer than "+maxRange.String(), http.StatusBadRequest)
+		return
+	}
+	source := storage.SourceCombined
+	for _, f := range req.AdhocFilters {
+		if f.Key == sourceTag && f.Operator == "=" {
+			source = f.Value
+		}
+	}
+	if !slices.Contains(sources, source) {
+		http.Error(w, "unknown source "+strconv.Quote(source), http.StatusBadRequest)
+		return
+	}
+	resolution := time.Duration(req.IntervalMs) * time.Millisecond
+	resolution = (resolution + storage.Resolution - 1).Truncate(storage.Resolution)
+
+	results := make([]interface{}, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		if t.Target == "" {
+			continue
+		}
+		result, err := s.query(r, t, source, req.Range, resolution)
+		if err != nil {
+			log.Printf("Failed to query %q: %v", t.Target, err)
+			http.Error(w, err.Error(), statusOf(err))
+			return
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, results)
+}
+
+func (s *server) handleAnnotations(w http.ResponseWriter, r *http.Request) {
+	var req annotationRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Range.To.Sub(req.Range.From) > maxRange {
+		http.Error(w, "time range is longer than "+maxRange.String(), http.StatusBadRequest)
+		return
+	}
+	annotations, err := s.annotations(r, req.Range, req.Annotation.Query)
+	if err != nil {
+		log.Printf("Failed to read annotations: %v", err)
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+	writeJSON(w, annotations)
+}
+
+func (s *server) handleTagKeys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, []tagKey{{Type: "string", Text: sourceTag}})
+}
+
+func (s *server) handleTagValues(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Key string `json:"key"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	values := []tagValue{}
+	if req.Key == sourceTag {
+		for _, source := range sources {
+			values = append(values, tagValue{Text: source})
+		}
+	}
+	writeJSON(w, values)
+}
+
+// decode reads the JSON body of r into v, answering the request itself when
+// it can't. An empty body leaves v untouched.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+func main() {
+	valkeyAddr := os.Getenv("VALKEY_ADDR")
+	if valkeyAddr == "" {
+		valkeyAddr = "valkey:6379"
+	}
+	addr := os.Getenv("HTTP_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	rdb := redis.NewClient(&redis.Options{
+		Addr: valkeyAddr,
+	})
+	s := &server{rdb: rdb}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleTest)
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/query", s.handleQuery)
+	mux.HandleFunc("/annotations", s.handleAnnotations)
+	mux.HandleFunc("/tag-keys", s.handleTagKeys)
+	mux.HandleFunc("/tag-values", s.handleTagValues)
+
+	log.Printf("Grafana datasource API listening at %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go-services/latency"
+	"go-services/storage"
+)
+
+// Targets are dot-separated:
+//
+//	tweets.<municipality>[.<condition>]  tweets per interval
+//	temperature.<municipality>           average temperature per interval
+//	humidity.<municipality>              average humidity per interval
+//	latency.<broker>.<stage>.<stat>      p50, p95, p99 or mean in milliseconds
+//	municipalities                       table of the lifetime stats
+//
+// where municipality may be "all" to add every municipality together. Counts
+// and averages come from the view chosen by the source ad hoc filter.
+const (
+	allMunicipalities = "all"
+	municipalityTable = "municipalities"
+)
+
+var latencyStats = map[string]func(latency.Histogram) time.Duration{
+	"p50":  func(h latency.Histogram) time.Duration { return h.Percentile(50) },
+	"p95":  func(h latency.Histogram) time.Duration { return h.Percentile(95) },
+	"p99":  func(h latency.Histogram) time.Duration { return h.Percentile(99) },
+	"mean": latency.Histogram.Mean,
+}
+
+// errBadTarget marks queries for targets that don't exist.
+var errBadTarget = errors.New("unknown target")
+
+func statusOf(err error) int {
+	if errors.Is(err, errBadTarget) || errors.Is(err, storage.ErrSeriesTooLong) {
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
+// targetNames lists every target /search offers.
+func targetNames() []string {
+	municipalities := append([]string{allMunicipalities}, storage.Municipalities...)
+	var names []string
+	for _, m := range municipalities {
+		names = append(names, "tweets."+m)
+		for _, c := range storage.Conditions {
+			names = append(names, "tweets."+m+"."+c)
+		}
+		names = append(names, "temperature."+m, "humidity."+m)
+	}
+	for _, broker := range []string{storage.SourceKafka, storage.SourceRabbitMQ} {
+		for _, stage := range latency.Stages {
+			for _, stat := range []string{"p50", "p95", "p99", "mean"} {
+				names = append(names, "latency."+broker+"."+stage+"."+stat)
+			}
+		}
+	}
+	return append(names, municipalityTable)
+}
+
+func (s *server) query(r *http.Request, t queryTarget, source string, rng timeRange, resolution time.Duration) (interface{}, error) {
+	parts := strings.Split(t.Target, ".")
+	switch {
+	case t.Target == municipalityTable:
+		return s.municipalityTable(r, source)
+	case parts[0] == "latency" && len(parts) == 4:
+		return s.latencySeries(r, t.Target, parts[1], parts[2], parts[3], rng, resolution)
+	case len(parts) == 2 || (parts[0] == "tweets" && len(parts) == 3):
+		return s.series(r, t.Target, parts, source, rng, resolution)
+	}
+	return nil, fmt.Errorf("%w %q", errBadTarget, t.Target)
+}
+
+func (s *server) series(r *http.Request, target string, parts []string, source string, rng timeRange, resolution time.Duration) (*timeSeries, error) {
+	var value func(storage.SeriesPoint) float64
+	switch parts[0] {
+	case "tweets":
+		value = func(p storage.SeriesPoint) float64 { return float64(p.Count) }
+		if len(parts) == 3 {
+			condition := parts[2]
+			if !slices.Contains(storage.Conditions, condition) {
+				return nil, fmt.Errorf("%w %q", errBadTarget, target)
+			}
+			value = func(p storage.SeriesPoint) float64 { return float64(p.Conditions[condition]) }
+		}
+	case "temperature":
+		value = func(p storage.SeriesPoint) float64 { return p.AverageTemperature() }
+	case "humidity":
+		value = func(p storage.SeriesPoint) float64 { return p.AverageHumidity() }
+	default:
+		return nil, fmt.Errorf("%w %q", errBadTarget, target)
+	}
+	municipalities := []string{parts[1]}
+	if parts[1] == allMunicipalities {
+		municipalities = storage.Municipalities
+	} else if !slices.Contains(storage.Municipalities, parts[1]) {
+		return nil, fmt.Errorf("%w %q", errBadTarget, target)
+	}
+
+	// Grafana's interval is only a hint: long ranges are read from hour or
+	// day buckets instead of one key per minute.
+	resolution = storage.SeriesResolution(rng.From, rng.To, resolution)
+	points, err := storage.ReadSeries(r.Context(), s.rdb, source, municipalities, rng.From, rng.To, resolution)
+	if err != nil {
+		return nil, err
+	}
+	ts := &timeSeries{Target: target, Datapoints: [][2]float64{}}
+	for _, p := range points {
+		// Averages of empty intervals are gaps, not zero degrees.
+		if parts[0] != "tweets" && p.Count == 0 {
+			continue
+		}
+		ts.Datapoints = append(ts.Datapoints, [2]float64{value(p), float64(p.Start.UnixMilli())})
+	}
+	return ts, nil
+}
+
+func (s *server) latencySeries(r *http.Request, target, broker, stage, stat string, rng timeRange, resolution time.Duration) (*timeSeries, error) {
+	value, ok := latencyStats[stat]
+	if !ok || (broker != storage.SourceKafka && broker != storage.SourceRabbitMQ) || !slices.Contains(latency.Stages, stage) {
+		return nil, fmt.Errorf("%w %q", errBadTarget, target)
+	}
+	resolution = storage.SeriesResolution(rng.From, rng.To, resolution)
+	points, err := storage.ReadLatencySeries(r.Context(), s.rdb, broker, stage, rng.From, rng.To, resolution)
+	if err != nil {
+		return nil, err
+	}
+	ts := &timeSeries{Target: target, Datapoints: [][2]float64{}}
+	for _, p := range points {
+		if p.Histogram.Count() == 0 {
+			continue
+		}
+		ms := float64(value(p.Histogram)) / float64(time.Millisecond)
+		ts.Datapoints = append(ts.Datapoints, [2]float64{ms, float64(p.Start.UnixMilli())})
+	}
+	return ts, nil
+}
+
+func (s *server) municipalityTable(r *http.Request, source string) (*table, error) {
+	stats, err := storage.ReadMunicipalityStats(r.Context(), s.rdb, source, storage.Municipalities)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{
+		Type: "table",
+		Columns: []tableColumn{
+			{Text: "Municipality", Type: "string"},
+			{Text: "Tweets", Type: "number"},
+			{Text: "Average temperature", Type: "number"},
+			{Text: "Average humidity", Type: "number"},
+		},
+	}
+	for _, c := range storage.Conditions {
+		t.Columns = append(t.Columns, tableColumn{Text: c, Type: "number"})
+	}
+	for _, m := range storage.Municipalities {
+		st := stats[m]
+		row := []interface{}{m, st.Count, st.AverageTemperature(), st.AverageHumidity()}
+		for _, c := range storage.Conditions {
+			row = append(row, st.Conditions[c])
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+var annotationTexts = map[string]string{
+	storage.EventDeadLettered:  "%d messages moved to the %s dead-letter queue",
+	storage.EventPublishFailed: "%d messages could not be published to %s",
+}
+
+// annotations marks the minutes in which a broker dead-lettered messages or
+// the gRPC server failed to publish to it, or the hours for ranges longer
+// than a day. query may name a single broker.
+func (s *server) annotations(r *http.Request, rng timeRange, query string) ([]annotation, error) {
+	annotations := []annotation{}
+	resolution := storage.SeriesResolution(rng.From, rng.To, storage.Resolution)
+	for _, broker := range []string{storage.SourceKafka, storage.SourceRabbitMQ} {
+		if query != "" && query != broker {
+			continue
+		}
+		stats, err := storage.ReadStatsSeries(r.Context(), s.rdb, broker, rng.From, rng.To, resolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range stats {
+			for _, event := range []string{storage.EventDeadLettered, storage.EventPublishFailed} {
+				n := m.Events[event]
+				if n == 0 {
+					continue
+				}
+				annotations = append(annotations, annotation{
+					Time:  m.Start.UnixMilli(),
+					Title: broker + " " + strings.ReplaceAll(event, "_", " "),
+					Text:  fmt.Sprintf(annotationTexts[event], n, broker),
+					Tags:  []string{broker, event},
+				})
+			}
+		}
+	}
+	return annotations, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"go-services/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// httpTweet is the JSON body of POST /tweet, as the Rust API accepts it.
+type httpTweet struct {
+	Municipality string `json:"municipality"`
+	Temperature  int32  `json:"temperature"`
+	Humidity     int32  `json:"humidity"`
+	Weather      string `json:"weather"`
+}
+
+// handleTweet ingests a tweet posted as JSON through the same path as
+// SendTweet, interceptors included, so the pipeline can be driven without
+// the Rust API. Unknown names map to the unknown enum values, as in the Rust
+// API.
+func (s *server) handleTweet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var tweet httpTweet
+	if err := json.NewDecoder(r.Body).Decode(&tweet); err != nil {
+		http.Error(w, "Json deserialize error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Received tweet via HTTP: %+v", tweet)
+
+	client := r.Header.Get("X-Client-ID")
+	if client == "" {
+		client = r.RemoteAddr
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), metadata.Pairs("x-client-id", client))
+	info := &grpc.UnaryServerInfo{Server: s, FullMethod: proto.WeatherTweetService_SendTweet_FullMethodName}
+	call := chainUnary(unaryInterceptors, info, func(ctx context.Context, req any) (any, error) {
+		return s.SendTweet(ctx, req.(*proto.WeatherTweetRequest))
+	})
+	resp, err := call(ctx, &proto.WeatherTweetRequest{
+		Municipality: proto.Municipalities(proto.Municipalities_value[strings.ToLower(tweet.Municipality)]),
+		Temperature:  tweet.Temperature,
+		Humidity:     tweet.Humidity,
+		Weather:      proto.Weathers(proto.Weathers_value[strings.ToLower(tweet.Weather)]),
+	})
+	if err != nil {
+		http.Error(w, "Failed to send tweet", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"go-services/proto"
+	"go-services/storage"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+func (s *statsServer) ListRecentTweets(ctx context.Context, in *proto.ListRecentTweetsRequest) (*proto.ListRecentTweetsResponse, error) {
+	pageSize := int64(in.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	municipality := municipalityName(in.Municipality)
+	tweets, next, err := storage.ListRecent(ctx, s.rdb, municipality, sourceName(in.Source), in.PageToken, pageSize)
+	if err == storage.ErrInvalidCursor {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	if err != nil {
+		log.Printf("Failed to read recent tweets: %v", err)
+		return nil, status.Error(codes.Unavailable, "failed to read recent tweets")
+	}
+
+	resp := &proto.ListRecentTweetsResponse{NextPageToken: next}
+	for _, t := range tweets {
+		resp.Tweets = append(resp.Tweets, &proto.RecentTweet{
+			Id:           t.ID,
+			Source:       proto.Sources(proto.Sources_value[t.Source]),
+			Municipality: in.Municipality,
+			Weather:      conditionValue(t.Condition),
+			Temperature:  int32(t.Temperature),
+			Humidity:     int32(t.Humidity),
+			ReceivedAt:   t.ReceivedAt,
+			StoredAt:     t.StoredAt().UnixMilli(),
+		})
+	}
+	return resp, nil
+}
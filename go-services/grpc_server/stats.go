@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go-services/proto"
+	"go-services/storage"
+
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxSeriesRange bounds GetTimeSeries to the default series retention.
+// storage.MaxSeriesBuckets further bounds how fine the resolution can be.
+const maxSeriesRange = 30 * 24 * time.Hour
+
+// statsServer answers queries about the stored tweets straight from Valkey.
+type statsServer struct {
+	proto.UnimplementedWeatherStatsServiceServer
+	rdb *redis.Client
+}
+
+func (s *statsServer) GetConditionCounts(ctx context.Context, in *proto.ConditionCountsRequest) (*proto.ConditionCountsResponse, error) {
+	counters, err := storage.ReadCounters(ctx, s.rdb, sourceName(in.Source))
+	if err != nil {
+		log.Printf("Failed to read condition counts: %v", err)
+		return nil, status.Error(codes.Unavailable, "failed to read condition counts")
+	}
+	return &proto.ConditionCountsResponse{Counts: conditionCounts(counters, storage.Conditions)}, nil
+}
+
+func (s *statsServer) GetMunicipalityStats(ctx context.Context, in *proto.MunicipalityStatsRequest) (*proto.MunicipalityStatsResponse, error) {
+	municipalities := storage.Municipalities
+	if len(in.Municipalities) > 0 {
+		municipalities = make([]string, len(in.Municipalities))
+		for i, m := range in.Municipalities {
+			municipalities[i] = municipalityName(m)
+		}
+	}
+	stats, err := storage.ReadMunicipalityStats(ctx, s.rdb, sourceName(in.Source), municipalities)
+	if err != nil {
+		log.Printf("Failed to read municipality stats: %v", err)
+		return nil, status.Error(codes.Unavailable, "failed to read municipality stats")
+	}
+
+	resp := &proto.MunicipalityStatsResponse{}
+	for _, municipality := range municipalities {
+		resp.Stats = append(resp.Stats, municipalityStats(municipality, stats[municipality], storage.Conditions))
+	}
+	return resp, nil
+}
+
+func (s *statsServer) GetTimeSeries(ctx context.Context, in *proto.TimeSeriesRequest) (*proto.TimeSeriesResponse, error) {
+	to := time.Now()
+	if in.To > 0 {
+		to = time.UnixMilli(in.To)
+	}
+	from := to.Add(-time.Hour)
+	if in.From > 0 {
+		from = time.UnixMilli(in.From)
+	}
+	if from.After(to) {
+		return nil, status.Error(codes.InvalidArgument, "from is after to")
+	}
+	if to.Sub(from) > maxSeriesRange {
+		return nil, status.Errorf(codes.InvalidArgument, "time range is longer than %s", maxSeriesRange)
+	}
+	// Without a resolution, the finest one the range allows.
+	resolution := storage.SeriesResolution(from, to, storage.Resolution)
+	if in.ResolutionSeconds != 0 {
+		resolution = time.Duration(in.ResolutionSeconds) * time.Second
+		if resolution < storage.Resolution || resolution%storage.Resolution != 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "resolution must be a multiple of %s", storage.Resolution)
+		}
+	}
+
+	municipalities := storage.Municipalities
+	if in.Municipality != nil {
+		municipalities = []string{municipalityName(in.GetMunicipality())}
+	}
+	points, err := storage.ReadSeries(ctx, s.rdb, sourceName(in.Source), municipalities, from, to, resolution)
+	if errors.Is(err, storage.ErrSeriesTooLong) {
+		return nil, status.Errorf(codes.InvalidArgument, "resolution too fine for the time range, try %s",
+			storage.SeriesResolution(from, to, resolution))
+	}
+	if err != nil {
+		log.Printf("Failed to read time series: %v", err)
+		return nil, status.Error(codes.Unavailable, "failed to read time series")
+	}
+
+	resp := &proto.TimeSeriesResponse{ResolutionSeconds: int64(resolution / time.Second)}
+	for _, p := range points {
+		count := p.Count
+		if in.Weather != nil {
+			count = p.Conditions[conditionName(in.GetWeather())]
+		}
+		resp.Points = append(resp.Points, &proto.TimeSeriesPoint{
+			Time:               p.Start.UnixMilli(),
+			Count:              count,
+			AverageTemperature: p.AverageTemperature(),
+			AverageHumidity:    p.AverageHumidity(),
+		})
+	}
+	return resp, nil
+}
+
+func municipalityStats(municipality string, m storage.MunicipalityStats, conditions []string) *proto.MunicipalityStats {
+	return &proto.MunicipalityStats{
+		Municipality:       municipalityValue(municipality),
+		Count:              m.Count,
+		AverageTemperature: m.AverageTemperature(),
+		AverageHumidity:    m.AverageHumidity(),
+		Conditions:         conditionCounts(m.Conditions, conditions),
+	}
+}
+
+// conditionCounts lists the counters of conditions in order, including
+// conditions without tweets.
+func conditionCounts(counters map[string]int64, conditions []string) []*proto.ConditionCount {
+	counts := make([]*proto.ConditionCount, len(conditions))
+	for i, condition := range conditions {
+		counts[i] = &proto.ConditionCount{Weather: conditionValue(condition), Count: counters[condition]}
+	}
+	return counts
+}
+
+func sourceName(source proto.Sources) string {
+	switch source {
+	case proto.Sources_kafka:
+		return storage.SourceKafka
+	case proto.Sources_rabbitmq:
+		return storage.SourceRabbitMQ
+	default:
+		return storage.SourceCombined
+	}
+}
+
+// The storage names match the enum names, except for the unknown values.
+
+func municipalityName(m proto.Municipalities) string {
+	if m == proto.Municipalities_municipalities_unknown {
+		return "unknown"
+	}
+	return m.String()
+}
+
+func municipalityValue(name string) proto.Municipalities {
+	return proto.Municipalities(proto.Municipalities_value[name])
+}
+
+func conditionName(w proto.Weathers) string {
+	if w == proto.Weathers_weathers_unknown {
+		return "unknown"
+	}
+	return w.String()
+}
+
+func conditionValue(name string) proto.Weathers {
+	return proto.Weathers(proto.Weathers_value[name])
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"go-services/proto"
+	"go-services/storage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	minWatchInterval     = 100 * time.Millisecond
+)
+
+// WatchStats sends the stats of the watched municipalities and then, at most
+// once per interval, those of the municipalities the consumers announced
+// changes for on storage.UpdatesChannel. Every client gets its own
+// subscription, so a slow client only delays itself.
+func (s *statsServer) WatchStats(in *proto.WatchStatsRequest, stream grpc.ServerStreamingServer[proto.StatsUpdate]) error {
+	ctx := stream.Context()
+	source := sourceName(in.Source)
+
+	municipalities := storage.Municipalities
+	if len(in.Municipalities) > 0 {
+		municipalities = nil
+		for _, m := range in.Municipalities {
+			municipalities = append(municipalities, municipalityName(m))
+		}
+	}
+	conditions := storage.Conditions
+	if len(in.Weathers) > 0 {
+		conditions = nil
+		for _, w := range in.Weathers {
+			conditions = append(conditions, conditionName(w))
+		}
+	}
+	interval := defaultWatchInterval
+	if in.IntervalMs > 0 {
+		interval = time.Duration(in.IntervalMs) * time.Millisecond
+		if interval < minWatchInterval {
+			interval = minWatchInterval
+		}
+	}
+
+	// Subscribe before the first read so no update falls in between.
+	pubsub := s.rdb.Subscribe(ctx, storage.UpdatesChannel)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Printf("Failed to subscribe to %s: %v", storage.UpdatesChannel, err)
+		return status.Error(codes.Unavailable, "failed to subscribe to updates")
+	}
+	updates := pubsub.Channel()
+
+	if err := s.sendStats(stream, source, municipalities, conditions); err != nil {
+		return err
+	}
+
+	watched := make(map[string]bool, len(municipalities))
+	for _, m := range municipalities {
+		watched[m] = true
+	}
+	relevant := make(map[string]bool, len(conditions))
+	for _, c := range conditions {
+		relevant[c] = true
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	changed := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-updates:
+			if !ok {
+				return status.Error(codes.Unavailable, "update subscription closed")
+			}
+			u, ok := storage.ParseUpdate(msg.Payload)
+			if ok && u.Source == source && watched[u.Municipality] && relevant[u.Condition] {
+				changed[u.Municipality] = true
+			}
+		case <-ticker.C:
+			if len(changed) == 0 {
+				continue
+			}
+			var batch []string
+			for _, m := range municipalities {
+				if changed[m] {
+					batch = append(batch, m)
+				}
+			}
+			changed = make(map[string]bool)
+			if err := s.sendStats(stream, source, batch, conditions); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *statsServer) sendStats(stream grpc.ServerStreamingServer[proto.StatsUpdate], source string, municipalities, conditions []string) error {
+	stats, err := storage.ReadMunicipalityStats(stream.Context(), s.rdb, source, municipalities)
+	if err != nil {
+		log.Printf("Failed to read municipality stats: %v", err)
+		return status.Error(codes.Unavailable, "failed to read municipality stats")
+	}
+	update := &proto.StatsUpdate{Time: time.Now().UnixMilli()}
+	for _, municipality := range municipalities {
+		update.Stats = append(update.Stats, municipalityStats(municipality, stats[municipality], conditions))
+	}
+	return stream.Send(update)
+}
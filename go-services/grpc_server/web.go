@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"go-services/proto"
+	"go-services/proto/protoconnect"
+
+	"connectrpc.com/connect"
+	connectcors "connectrpc.com/cors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The services are also served over HTTP, for browsers: the Connect
+// handlers speak the Connect, gRPC-Web and gRPC protocols and hand every
+// call to the same handlers as the grpc.Server, through the same
+// interceptors.
+
+type webTweetService struct {
+	srv *server
+}
+
+func (w webTweetService) SendTweet(ctx context.Context, req *connect.Request[proto.WeatherTweetRequest]) (*connect.Response[proto.WeatherTweetResponse], error) {
+	return callUnary(ctx, w.srv, proto.WeatherTweetService_SendTweet_FullMethodName, req, w.srv.SendTweet)
+}
+
+type webStatsService struct {
+	srv *statsServer
+}
+
+func (w webStatsService) GetConditionCounts(ctx context.Context, req *connect.Request[proto.ConditionCountsRequest]) (*connect.Response[proto.ConditionCountsResponse], error) {
+	return callUnary(ctx, w.srv, proto.WeatherStatsService_GetConditionCounts_FullMethodName, req, w.srv.GetConditionCounts)
+}
+
+func (w webStatsService) GetMunicipalityStats(ctx context.Context, req *connect.Request[proto.MunicipalityStatsRequest]) (*connect.Response[proto.MunicipalityStatsResponse], error) {
+	return callUnary(ctx, w.srv, proto.WeatherStatsService_GetMunicipalityStats_FullMethodName, req, w.srv.GetMunicipalityStats)
+}
+
+func (w webStatsService) GetTimeSeries(ctx context.Context, req *connect.Request[proto.TimeSeriesRequest]) (*connect.Response[proto.TimeSeriesResponse], error) {
+	return callUnary(ctx, w.srv, proto.WeatherStatsService_GetTimeSeries_FullMethodName, req, w.srv.GetTimeSeries)
+}
+
+func (w webStatsService) ListRecentTweets(ctx context.Context, req *connect.Request[proto.ListRecentTweetsRequest]) (*connect.Response[proto.ListRecentTweetsResponse], error) {
+	return callUnary(ctx, w.srv, proto.WeatherStatsService_ListRecentTweets_FullMethodName, req, w.srv.ListRecentTweets)
+}
+
+func (w webStatsService) WatchStats(ctx context.Context, req *connect.Request[proto.WatchStatsRequest], stream *connect.ServerStream[proto.StatsUpdate]) error {
+	ss := &webStream[proto.StatsUpdate]{
+		ctx:    metadata.NewIncomingContext(ctx, headerMetadata(req.Header())),
+		stream: stream,
+	}
+	info := &grpc.StreamServerInfo{FullMethod: proto.WeatherStatsService_WatchStats_FullMethodName, IsServerStream: true}
+	call := chainStream(streamInterceptors, info, func(srv any, ss grpc.ServerStream) error {
+		return w.srv.WatchStats(req.Msg, &grpc.GenericServerStream[proto.WatchStatsRequest, proto.StatsUpdate]{ServerStream: ss})
+	})
+	return connectError(call(w.srv, ss))
+}
+
+// callUnary runs a native unary handler, and the interceptors, for a
+// Connect request.
+func callUnary[Req, Res any](ctx context.Context, srv any, method string, req *connect.Request[Req], handler func(context.Context, *Req) (*Res, error)) (*connect.Response[Res], error) {
+	ctx = metadata.NewIncomingContext(ctx, headerMetadata(req.Header()))
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: method}
+	call := chainUnary(unaryInterceptors, info, func(ctx context.Context, in any) (any, error) {
+		return handler(ctx, in.(*Req))
+	})
+	out, err := call(ctx, req.Msg)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(out.(*Res)), nil
+}
+
+// webStream lets native server-streaming handlers send on a Connect stream.
+type webStream[Res any] struct {
+	ctx    context.Context
+	stream *connect.ServerStream[Res]
+}
+
+func (s *webStream[Res]) Context() context.Context { return s.ctx }
+
+func (s *webStream[Res]) SendMsg(m any) error { return s.stream.Send(m.(*Res)) }
+
+// RecvMsg has nothing to return: the only request was already read.
+func (s *webStream[Res]) RecvMsg(m any) error { return io.EOF }
+
+func (s *webStream[Res]) SetHeader(md metadata.MD) error {
+	copyMetadata(s.stream.ResponseHeader(), md)
+	return nil
+}
+
+// SendHeader only sets the headers; Connect sends them with the first
+// message.
+func (s *webStream[Res]) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *webStream[Res]) SetTrailer(md metadata.MD) {
+	copyMetadata(s.stream.ResponseTrailer(), md)
+}
+
+func headerMetadata(h http.Header) metadata.MD {
+	md := make(metadata.MD, len(h))
+	for k, v := range h {
+		md[strings.ToLower(k)] = v
+	}
+	return md
+}
+
+func copyMetadata(h http.Header, md metadata.MD) {
+	for k, v := range md {
+		for _, value := range v {
+			h.Add(k, value)
+		}
+	}
+}
+
+// connectError carries the gRPC status code of err over to Connect; the
+// codes are the same.
+func connectError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return err
+	}
+	if st, ok := status.FromError(err); ok {
+		return connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	}
+	return connect.NewError(connect.CodeUnknown, err)
+}
+
+// withCORS lets the origins in CORS_ALLOWED_ORIGINS, a comma-separated list,
+// call the services from a browser. Without it no other origin may, since
+// SendTweet would otherwise be open to any web page; "*" has to be listed
+// explicitly.
+func withCORS(h http.Handler) http.Handler {
+	origins := make(map[string]bool)
+	for _, o := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins[o] = true
+		}
+	}
+	if len(origins) == 0 {
+		log.Printf("CORS_ALLOWED_ORIGINS is not set: refusing cross-origin requests")
+	}
+	methods := strings.Join(connectcors.AllowedMethods(), ", ")
+	headers := strings.Join(connectcors.AllowedHeaders(), ", ")
+	exposed := strings.Join(connectcors.ExposedHeaders(), ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !(origins["*"] || origins[origin]) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Max-Age", "7200")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", exposed)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// newHTTPServer serves the JSON gateway and the Connect handlers on addr,
+// over HTTP/1.1 and unencrypted HTTP/2, which gRPC clients need.
+func newHTTPServer(addr string, tweets *server, stats *statsServer) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tweet", tweets.handleTweet)
+	mux.Handle(protoconnect.NewWeatherTweetServiceHandler(webTweetService{srv: tweets}))
+	mux.Handle(protoconnect.NewWeatherStatsServiceHandler(webStatsService{srv: stats}))
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Server{
+		Addr:      addr,
+		Handler:   withCORS(mux),
+		Protocols: &protocols,
+	}
+}
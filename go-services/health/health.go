@@ -0,0 +1,268 @@
+// Package health exposes the liveness, readiness and status endpoints of the
+// consumers.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go-services/latency"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Server tracks the state of a consumer and serves it over HTTP:
+//
+//	/healthz  200 while the broker connection is up
+//	/readyz   200 while connected, subscribed and Valkey answers PING
+//	/status   JSON with assignments, last message time, error counts and
+//	          latency percentiles
+//	/metrics  the same counters and latencies in Prometheus text format
+type Server struct {
+	broker    string
+	rdb       *redis.Client
+	startedAt time.Time
+
+	mu          sync.Mutex
+	connected   bool
+	subscribed  bool
+	assignments []string
+	lastMessage time.Time
+	processed   int64
+	errors      map[string]int64
+	latencies   map[string]latency.Histogram
+}
+
+// LatencySummary describes the latency of one pipeline stage.
+type LatencySummary struct {
+	Count int64   `json:"count"`
+	P50   float64 `json:"p50_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+}
+
+// Status is the body of /status.
+type Status struct {
+	Broker      string                    `json:"broker"`
+	Connected   bool                      `json:"connected"`
+	Subscribed  bool                      `json:"subscribed"`
+	Valkey      string                    `json:"valkey"`
+	Assignments []string                  `json:"assignments"`
+	LastMessage *time.Time                `json:"last_message_at,omitempty"`
+	Processed   int64                     `json:"processed"`
+	Errors      map[string]int64          `json:"errors"`
+	Latency     map[string]LatencySummary `json:"latency"`
+	StartedAt   time.Time                 `json:"started_at"`
+}
+
+// New returns a Server for a consumer of broker storing into rdb.
+func New(broker string, rdb *redis.Client) *Server {
+	return &Server{
+		broker:    broker,
+		rdb:       rdb,
+		startedAt: time.Now(),
+		errors:    make(map[string]int64),
+		latencies: make(map[string]latency.Histogram),
+	}
+}
+
+// SetConnected records whether the broker connection is up. Losing the
+// connection also drops the subscription.
+func (s *Server) SetConnected(connected bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = connected
+	if !connected {
+		s.subscribed = false
+	}
+}
+
+// SetSubscribed records whether the consumer is receiving from its queue or
+// topic.
+func (s *Server) SetSubscribed(subscribed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribed = subscribed
+}
+
+// SetAssignments replaces the list of partitions or queues being consumed.
+func (s *Server) SetAssignments(assignments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assignments = append([]string(nil), assignments...)
+	sort.Strings(s.assignments)
+}
+
+// MessageProcessed counts a message that was stored successfully.
+func (s *Server) MessageProcessed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processed++
+	s.lastMessage = time.Now()
+}
+
+// RecordError counts an error of the given kind.
+func (s *Server) RecordError(kind string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[kind]++
+}
+
+// ObserveLatency records the latency of a pipeline stage.
+func (s *Server) ObserveLatency(stage string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.latencies[stage]
+	if !ok {
+		h = make(latency.Histogram)
+		s.latencies[stage] = h
+	}
+	h.Record(d)
+}
+
+// ListenAndServe serves the endpoints on addr in the background.
+func (s *Server) ListenAndServe(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	go func() {
+		log.Printf("Health endpoints listening at %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("Health server stopped: %v", err)
+		}
+	}()
+}
+
+func (s *Server) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	return s.rdb.Ping(ctx).Err()
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	connected := s.connected
+	s.mu.Unlock()
+	if !connected {
+		http.Error(w, s.broker+" disconnected", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	connected, subscribed := s.connected, s.subscribed
+	s.mu.Unlock()
+	switch {
+	case !connected:
+		http.Error(w, s.broker+" disconnected", http.StatusServiceUnavailable)
+	case !subscribed:
+		http.Error(w, "not subscribed", http.StatusServiceUnavailable)
+	default:
+		if err := s.ping(r.Context()); err != nil {
+			http.Error(w, "valkey: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	valkey := "ok"
+	if err := s.ping(r.Context()); err != nil {
+		valkey = err.Error()
+	}
+
+	s.mu.Lock()
+	status := Status{
+		Broker:      s.broker,
+		Connected:   s.connected,
+		Subscribed:  s.subscribed,
+		Valkey:      valkey,
+		Assignments: append([]string{}, s.assignments...),
+		Processed:   s.processed,
+		Errors:      make(map[string]int64, len(s.errors)),
+		Latency:     make(map[string]LatencySummary, len(s.latencies)),
+		StartedAt:   s.startedAt,
+	}
+	for k, v := range s.errors {
+		status.Errors[k] = v
+	}
+	for stage, h := range s.latencies {
+		status.Latency[stage] = LatencySummary{
+			Count: h.Count(),
+			P50:   milliseconds(h.Percentile(50)),
+			P95:   milliseconds(h.Percentile(95)),
+			P99:   milliseconds(h.Percentile(99)),
+		}
+	}
+	if !s.lastMessage.IsZero() {
+		last := s.lastMessage
+		status.LastMessage = &last
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// metricBuckets are the Prometheus histogram bounds, in seconds, the HDR
+// buckets are folded into.
+var metricBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# TYPE weather_consumer_processed_total counter\n")
+	fmt.Fprintf(w, "weather_consumer_processed_total{broker=%q} %d\n", s.broker, s.processed)
+
+	fmt.Fprintf(w, "# TYPE weather_consumer_errors_total counter\n")
+	for _, kind := range sortedKeys(s.errors) {
+		fmt.Fprintf(w, "weather_consumer_errors_total{broker=%q,kind=%q} %d\n", s.broker, kind, s.errors[kind])
+	}
+
+	fmt.Fprintf(w, "# TYPE weather_consumer_latency_seconds histogram\n")
+	for _, stage := range sortedKeys(s.latencies) {
+		h := s.latencies[stage]
+		var sum float64
+		for bucket, n := range h {
+			sum += float64(bucket) / 1e6 * float64(n)
+		}
+		for _, le := range metricBuckets {
+			var cumulative int64
+			for bucket, n := range h {
+				if float64(bucket)/1e6 <= le {
+					cumulative += n
+				}
+			}
+			fmt.Fprintf(w, "weather_consumer_latency_seconds_bucket{broker=%q,stage=%q,le=\"%g\"} %d\n", s.broker, stage, le, cumulative)
+		}
+		fmt.Fprintf(w, "weather_consumer_latency_seconds_bucket{broker=%q,stage=%q,le=\"+Inf\"} %d\n", s.broker, stage, h.Count())
+		fmt.Fprintf(w, "weather_consumer_latency_seconds_sum{broker=%q,stage=%q} %g\n", s.broker, stage, sum)
+		fmt.Fprintf(w, "weather_consumer_latency_seconds_count{broker=%q,stage=%q} %d\n", s.broker, stage, h.Count())
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -2,24 +2,31 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"go-services/health"
+	"go-services/latency"
+	"go-services/messaging"
+	"go-services/storage"
+	"go-services/workerpool"
+
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/go-redis/redis/v8"
 )
 
 var ctx = context.Background()
 
-type Tweet struct {
-	Municipality int32 `json:"municipality"`
-	Temperature  int32 `json:"temperature"`
-	Humidity     int32 `json:"humidity"`
-	Weather      int32 `json:"weather"`
-}
+// probes backs the /healthz, /readyz and /status endpoints.
+var probes *health.Server
+
+// assigned holds the partitions this consumer owns. It is only touched from
+// the rebalance callback.
+var assigned = make(map[string]bool)
 
 func main() {
 	kafkaBroker := os.Getenv("KAFKA_BROKER")
@@ -32,63 +39,302 @@ func main() {
 		valkeyAddr = "valkey:6379"
 	}
 
+	maxRetries := storage.GetEnvInt("VALKEY_MAX_RETRIES", 5)
+	commitInterval := time.Duration(storage.GetEnvInt("COMMIT_INTERVAL_MS", 1000)) * time.Millisecond
+	workers := storage.GetEnvInt("WORKERS", 4)
+	workerQueue := storage.GetEnvInt("WORKER_QUEUE_SIZE", 100)
+
+	healthAddr := os.Getenv("HEALTH_ADDR")
+	if healthAddr == "" {
+		healthAddr = ":8080"
+	}
+
 	rdb := redis.NewClient(&redis.Options{
 		Addr: valkeyAddr,
 	})
 
-	c, err := kafka.NewConsumer(&kafka.ConfigMap{
+	probes = health.New("kafka", rdb)
+	probes.ListenAndServe(healthAddr)
+
+	store := storage.New(rdb, storage.SourceKafka)
+	store.ObserveLatency(probes.ObserveLatency)
+	if err := store.Init(ctx); err != nil {
+		log.Fatalf("Failed to prepare Valkey: %v", err)
+	}
+
+	assignmentStrategy := os.Getenv("KAFKA_ASSIGNMENT_STRATEGY")
+	if assignmentStrategy == "" {
+		assignmentStrategy = "cooperative-sticky"
+	}
+
+	config := &kafka.ConfigMap{
 		"bootstrap.servers": kafkaBroker,
 		"group.id":          "weather-consumer-group",
 		"auto.offset.reset": "earliest",
-	})
+		// Offsets are committed by hand once the tweet is stored in Valkey.
+		"enable.auto.commit": false,
+		// Cooperative rebalancing only moves the partitions that change
+		// owner, so scaling the Deployment doesn't stop every consumer.
+		"partition.assignment.strategy": assignmentStrategy,
+	}
+	// Static membership is opt-in: a consumer that restarts under the same
+	// KAFKA_GROUP_INSTANCE_ID gets its partitions back without a rebalance,
+	// but one that stops for good keeps them unowned until the session
+	// times out, since it no longer leaves the group on close. Only set it
+	// where names are stable, e.g. from the pod name of a StatefulSet.
+	if instanceID := os.Getenv("KAFKA_GROUP_INSTANCE_ID"); instanceID != "" {
+		config.SetKey("group.instance.id", instanceID)
+	}
 
+	c, err := kafka.NewConsumer(config)
 	if err != nil {
 		log.Fatalf("Failed to create consumer: %s", err)
 	}
+	probes.SetConnected(true)
+
+	dlq, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers": kafkaBroker,
+		"acks":              "all",
+	})
+	if err != nil {
+		log.Fatalf("Failed to create dead-letter producer: %s", err)
+	}
+
+	offsets := newOffsetTracker()
 
-	c.SubscribeTopics([]string{"weather-tweets"}, nil)
+	err = c.SubscribeTopics([]string{messaging.TweetsTopic}, func(c *kafka.Consumer, ev kafka.Event) error {
+		return rebalance(c, ev, offsets)
+	})
+	if err != nil {
+		log.Fatalf("Failed to subscribe: %s", err)
+	}
+	probes.SetSubscribed(true)
 	log.Println("Kafka consumer started. Waiting for messages...")
 
-	for {
+	lastCommit := time.Now()
+	pool := workerpool.New(workers, workerQueue)
+
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
+	// stop ends the dead-letter retries of the workers on shutdown.
+	stop := make(chan struct{})
+
+	connected := true
+	run := true
+	for run {
+		select {
+		case sig := <-sigchan:
+			log.Printf("Caught signal %v: shutting down", sig)
+			close(stop)
+			run = false
+			continue
+		default:
+		}
+
 		msg, err := c.ReadMessage(time.Second)
 		if err == nil {
-			log.Printf("Received from Kafka: %s", string(msg.Value))
-			var tweet Tweet
-			if err := json.Unmarshal(msg.Value, &tweet); err != nil {
-				log.Printf("Error unmarshalling tweet: %v", err)
-				continue
+			if !connected {
+				setConnected(&connected)
 			}
-
-			// Store in Valkey
-			// Example: store total reports per weather condition
-			weatherCondition := getWeatherCondition(tweet.Weather)
-			err = rdb.Incr(ctx, fmt.Sprintf("weather:%s", weatherCondition)).Err()
-			if err != nil {
-				log.Printf("Failed to increment weather condition count in Valkey: %v", err)
-			} else {
-				log.Printf("Incremented count for weather: %s", weatherCondition)
+			offsets.start(msg.TopicPartition)
+			tweet, decodeErr := messaging.DecodeKafkaTweet(msg)
+			pool.Submit(messaging.OrderingKey(tweet, decodeErr), func() {
+				if err := processMessage(store, msg, tweet, decodeErr, maxRetries); err != nil {
+					log.Printf("Failed to process message at %v: %v", msg.TopicPartition, err)
+					store.Count(ctx, storage.EventFailed)
+					if !deadLetter(dlq, offsets, msg, err, stop) {
+						return
+					}
+					store.Count(ctx, storage.EventDeadLettered)
+				}
+				offsets.markDone(msg.TopicPartition)
+			})
+		} else if kerr, ok := err.(kafka.Error); ok && kerr.IsTimeout() {
+			// An idle consumer gets no messages to prove the brokers are
+			// back, so ask them for the topic metadata instead.
+			if !connected && brokersReachable(c) {
+				setConnected(&connected)
 			}
-
-		} else if !err.(kafka.Error).IsTimeout() {
+		} else {
 			// The client will automatically try to recover from all errors.
 			log.Printf("Consumer error: %v (%v)\n", err, msg)
+			probes.RecordError("consumer")
+			if ok && kerr.Code() == kafka.ErrAllBrokersDown {
+				connected = false
+				probes.SetConnected(false)
+			}
 		}
+
+		if time.Since(lastCommit) >= commitInterval {
+			offsets.commit(c)
+			lastCommit = time.Now()
+		}
+	}
+
+	// Let the workers finish what they already have, commit what they stored
+	// and leave the group so the partitions are reassigned right away. Static
+	// members don't leave: their partitions wait for them until the session
+	// times out.
+	pool.Close()
+	offsets.commit(c)
+	if err := c.Close(); err != nil {
+		log.Printf("Failed to close consumer: %v", err)
 	}
+	dlq.Flush(15 * 1000)
+	dlq.Close()
+	rdb.Close()
+	log.Println("Kafka consumer stopped")
+}
 
-	c.Close()
+// setConnected marks the brokers as reachable again after ErrAllBrokersDown.
+func setConnected(connected *bool) {
+	log.Printf("Kafka brokers reachable again")
+	*connected = true
+	probes.SetConnected(true)
+	probes.SetSubscribed(true)
 }
 
-func getWeatherCondition(weather int32) string {
-	switch weather {
-	case 1:
-		return "sunny"
-	case 2:
-		return "cloudy"
-	case 3:
-		return "rainy"
-	case 4:
-		return "foggy"
-	default:
-		return "unknown"
+// brokersReachable reports whether a broker answers a metadata request.
+func brokersReachable(c *kafka.Consumer) bool {
+	topic := messaging.TweetsTopic
+	_, err := c.GetMetadata(&topic, false, 500)
+	return err == nil
+}
+
+// rebalance commits what was stored for partitions that are being taken away
+// and starts clean on newly assigned ones. The client applies the assignment
+// itself, incrementally when the cooperative protocol is in use.
+func rebalance(c *kafka.Consumer, ev kafka.Event, offsets *offsetTracker) error {
+	switch e := ev.(type) {
+	case kafka.AssignedPartitions:
+		log.Printf("Assigned partitions (%s): %v", c.GetRebalanceProtocol(), e.Partitions)
+		// Only the group coordinator hands out partitions.
+		probes.SetConnected(true)
+		probes.SetSubscribed(true)
+		offsets.reset(e.Partitions)
+		for _, tp := range e.Partitions {
+			assigned[fmt.Sprintf("%s[%d]", *tp.Topic, tp.Partition)] = true
+		}
+	case kafka.RevokedPartitions:
+		log.Printf("Revoked partitions (%s): %v", c.GetRebalanceProtocol(), e.Partitions)
+		if !offsets.waitIdle(e.Partitions, 10*time.Second) {
+			log.Printf("Timed out waiting for in-flight messages of revoked partitions")
+		}
+		if !c.AssignmentLost() {
+			offsets.commit(c)
+		}
+		offsets.reset(e.Partitions)
+		for _, tp := range e.Partitions {
+			delete(assigned, fmt.Sprintf("%s[%d]", *tp.Topic, tp.Partition))
+		}
+	}
+
+	partitions := make([]string, 0, len(assigned))
+	for p := range assigned {
+		partitions = append(partitions, p)
+	}
+	probes.SetAssignments(partitions)
+	return nil
+}
+
+// processError describes why a record could not be stored, for the
+// dead-letter headers.
+type processError struct {
+	reason   string
+	attempts int
+	err      error
+}
+
+func (e *processError) Error() string { return e.err.Error() }
+
+func (e *processError) Unwrap() error { return e.err }
+
+// deadLetter sends msg to the dead-letter topic and reports whether it got
+// there, i.e. whether it is safe to commit past it. A failed produce blocks
+// the partition at msg and is retried with backoff; once it succeeds the
+// partition is unblocked. If stop is closed first the partition stays blocked,
+// so a restart replays the message instead of skipping it.
+func deadLetter(p *kafka.Producer, offsets *offsetTracker, msg *kafka.Message, err error, stop <-chan struct{}) bool {
+	reason, attempts := messaging.ReasonStorageFailed, 1
+	if perr, ok := err.(*processError); ok {
+		reason, attempts = perr.reason, perr.attempts
+	}
+	probes.RecordError(reason)
+	blocked := false
+	backoff := 100 * time.Millisecond
+	for {
+		derr := messaging.DeadLetterKafka(p, msg, reason, err, attempts)
+		if derr == nil {
+			break
+		}
+		log.Printf("Failed to dead-letter message at %v, retrying in %v: %v", msg.TopicPartition, backoff, derr)
+		probes.RecordError("dead-letter-failed")
+		if !blocked {
+			offsets.block(msg.TopicPartition)
+			blocked = true
+		}
+		select {
+		case <-stop:
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+	if blocked {
+		offsets.unblock(msg.TopicPartition)
+	}
+	log.Printf("Dead-lettered message at %v (%s)", msg.TopicPartition, reason)
+	return true
+}
+
+// processMessage stores the tweet decoded from msg in Valkey, retrying
+// transient failures. decodeErr is why msg could not be decoded, if so.
+func processMessage(store *storage.Store, msg *kafka.Message, tweet messaging.Tweet, decodeErr error, maxRetries int) error {
+	log.Printf("Received from Kafka: %s", string(msg.Value))
+	publishedAt, ok := messaging.HeaderInt64(msg, messaging.HeaderPublishedAt)
+	if !ok && msg.TimestampType == kafka.TimestampCreateTime {
+		publishedAt = msg.Timestamp.UnixMilli()
+	}
+	store.RecordLatency(ctx, latency.StagePublishToConsume, publishedAt)
+
+	if decodeErr != nil {
+		return &processError{reason: messaging.ReasonMalformed, attempts: 1, err: fmt.Errorf("unmarshalling tweet: %w", decodeErr)}
+	}
+
+	if tweet.ID == "" {
+		tweet.ID = string(messaging.Header(msg, messaging.HeaderTweetID))
+	}
+	if tweet.ReceivedAt == 0 {
+		tweet.ReceivedAt, _ = messaging.HeaderInt64(msg, messaging.HeaderReceivedAt)
+	}
+
+	// Store in Valkey
+	// Example: store total reports per weather condition
+	weatherCondition := tweet.ConditionName()
+	stored := false
+	attempts, err := storage.Retry(maxRetries, func() error {
+		var err error
+		stored, err = store.Record(ctx, storage.Tweet{
+			ID:           tweet.ID,
+			Municipality: tweet.MunicipalityName(),
+			Condition:    weatherCondition,
+			Temperature:  tweet.Temperature,
+			Humidity:     tweet.Humidity,
+			ReceivedAt:   tweet.ReceivedTime(),
+		})
+		return err
+	})
+	if err != nil {
+		return &processError{reason: messaging.ReasonStorageFailed, attempts: attempts, err: fmt.Errorf("incrementing weather condition count in Valkey: %w", err)}
+	}
+	if !stored {
+		log.Printf("Skipping tweet %s: already stored", tweet.ID)
+		return nil
 	}
+	log.Printf("Incremented count for weather: %s", weatherCondition)
+	probes.MessageProcessed()
+	store.RecordLatency(ctx, latency.StageReceiveToStored, tweet.ReceivedAt)
+	return nil
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// partitionState tracks the messages of one partition that are being worked
+// on. Workers finish them out of order, so the committable offset is the
+// lowest one still in flight.
+type partitionState struct {
+	inflight  map[kafka.Offset]struct{}
+	blocked   map[kafka.Offset]struct{}
+	next      kafka.Offset
+	committed kafka.Offset
+}
+
+func (s *partitionState) committable() kafka.Offset {
+	offset := s.next
+	for o := range s.inflight {
+		if o < offset {
+			offset = o
+		}
+	}
+	for o := range s.blocked {
+		if o < offset {
+			offset = o
+		}
+	}
+	return offset
+}
+
+// offsetTracker keeps, per partition, the next offset that is safe to commit.
+// An offset only becomes committable once every message before it has been
+// stored in Valkey. A partition holding a poison message that could not be
+// dead-lettered yet stays blocked at that offset, so a restart replays it
+// instead of skipping it.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionState
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[partitionKey]*partitionState)}
+}
+
+func (t *offsetTracker) state(tp kafka.TopicPartition) *partitionState {
+	key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+	s, ok := t.partitions[key]
+	if !ok {
+		s = &partitionState{
+			inflight:  make(map[kafka.Offset]struct{}),
+			blocked:   make(map[kafka.Offset]struct{}),
+			committed: kafka.OffsetInvalid,
+		}
+		t.partitions[key] = s
+	}
+	return s
+}
+
+// start records that the message at tp has been handed to a worker.
+func (t *offsetTracker) start(tp kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.state(tp)
+	s.inflight[tp.Offset] = struct{}{}
+	if tp.Offset >= s.next {
+		s.next = tp.Offset + 1
+	}
+}
+
+// markDone records that the message at tp has been processed.
+func (t *offsetTracker) markDone(tp kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]; ok {
+		delete(s.inflight, tp.Offset)
+	}
+}
+
+// block stops committing past the message at tp until it is unblocked.
+func (t *offsetTracker) block(tp kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	if !ok {
+		return
+	}
+	delete(s.inflight, tp.Offset)
+	s.blocked[tp.Offset] = struct{}{}
+	log.Printf("Partition %s[%d] blocked at offset %v until the message is dead-lettered", *tp.Topic, tp.Partition, tp.Offset)
+}
+
+// unblock records that the blocked message at tp has been dead-lettered after
+// all, so the partition can be committed past it again.
+func (t *offsetTracker) unblock(tp kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	if !ok {
+		return
+	}
+	if _, blocked := s.blocked[tp.Offset]; blocked {
+		delete(s.blocked, tp.Offset)
+		log.Printf("Partition %s[%d] unblocked at offset %v", *tp.Topic, tp.Partition, tp.Offset)
+	}
+}
+
+// reset drops whatever is known about partitions, e.g. after they have been
+// revoked or freshly assigned.
+func (t *offsetTracker) reset(partitions []kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tp := range partitions {
+		delete(t.partitions, partitionKey{topic: *tp.Topic, partition: tp.Partition})
+	}
+}
+
+// waitIdle waits until no message of partitions is in flight, or timeout.
+func (t *offsetTracker) waitIdle(partitions []kafka.TopicPartition, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		t.mu.Lock()
+		busy := false
+		for _, tp := range partitions {
+			if s, ok := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]; ok && len(s.inflight) > 0 {
+				busy = true
+				break
+			}
+		}
+		t.mu.Unlock()
+		if !busy {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// commit synchronously commits every partition whose committable offset moved
+// since the last commit.
+func (t *offsetTracker) commit(c *kafka.Consumer) {
+	t.mu.Lock()
+	var offsets []kafka.TopicPartition
+	for key, s := range t.partitions {
+		offset := s.committable()
+		if offset == s.committed {
+			continue
+		}
+		topic := key.topic
+		offsets = append(offsets, kafka.TopicPartition{Topic: &topic, Partition: key.partition, Offset: offset})
+	}
+	t.mu.Unlock()
+	if len(offsets) == 0 {
+		return
+	}
+
+	committed, err := c.CommitOffsets(offsets)
+	if err != nil {
+		log.Printf("Failed to commit offsets: %v", err)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tp := range committed {
+		if tp.Error != nil {
+			log.Printf("Failed to commit offset for %s[%d]: %v", *tp.Topic, tp.Partition, tp.Error)
+			continue
+		}
+		t.state(tp).committed = tp.Offset
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+type offsetStep struct {
+	op     string // start, done, block, unblock or reset
+	offset kafka.Offset
+}
+
+func testPartition(offset kafka.Offset) kafka.TopicPartition {
+	topic := "weather-tweets"
+	return kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: offset}
+}
+
+// committableFor returns what commit would send for the test partition, or
+// OffsetInvalid when nothing is tracked for it.
+func (t *offsetTracker) committableFor(tp kafka.TopicPartition) kafka.Offset {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	if !ok {
+		return kafka.OffsetInvalid
+	}
+	return s.committable()
+}
+
+func TestOffsetTracker(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []offsetStep
+		want  kafka.Offset
+	}{
+		{
+			name:  "in order",
+			steps: []offsetStep{{"start", 0}, {"start", 1}, {"done", 0}, {"done", 1}},
+			want:  2,
+		},
+		{
+			name:  "out of order keeps the lowest in flight",
+			steps: []offsetStep{{"start", 0}, {"start", 1}, {"start", 2}, {"done", 2}, {"done", 1}},
+			want:  0,
+		},
+		{
+			name:  "out of order catches up once the lowest is done",
+			steps: []offsetStep{{"start", 0}, {"start", 1}, {"start", 2}, {"done", 2}, {"done", 1}, {"done", 0}},
+			want:  3,
+		},
+		{
+			name:  "gap between in flight messages",
+			steps: []offsetStep{{"start", 4}, {"start", 5}, {"start", 6}, {"done", 4}, {"done", 6}},
+			want:  5,
+		},
+		{
+			name:  "blocked partition stays at the poison message",
+			steps: []offsetStep{{"start", 5}, {"start", 6}, {"start", 7}, {"block", 6}, {"done", 5}, {"done", 7}},
+			want:  6,
+		},
+		{
+			name:  "blocked partition ignores later messages",
+			steps: []offsetStep{{"start", 5}, {"block", 5}, {"start", 6}, {"done", 6}, {"start", 7}, {"done", 7}},
+			want:  5,
+		},
+		{
+			name:  "lowest block wins",
+			steps: []offsetStep{{"start", 6}, {"start", 7}, {"block", 7}, {"block", 6}},
+			want:  6,
+		},
+		{
+			name:  "in flight below the block",
+			steps: []offsetStep{{"start", 3}, {"start", 4}, {"block", 4}},
+			want:  3,
+		},
+		{
+			name:  "blocked partition unblocks once the message is dead-lettered",
+			steps: []offsetStep{{"start", 5}, {"start", 6}, {"start", 7}, {"block", 6}, {"done", 5}, {"done", 7}, {"unblock", 6}},
+			want:  8,
+		},
+		{
+			name:  "unblocking one message keeps the other blocked",
+			steps: []offsetStep{{"start", 5}, {"start", 6}, {"block", 5}, {"block", 6}, {"unblock", 5}},
+			want:  6,
+		},
+		{
+			name:  "reset drops in flight messages",
+			steps: []offsetStep{{"start", 0}, {"start", 1}, {"reset", 0}},
+			want:  kafka.OffsetInvalid,
+		},
+		{
+			name:  "done after reset is ignored",
+			steps: []offsetStep{{"start", 0}, {"start", 1}, {"reset", 0}, {"done", 0}, {"done", 1}},
+			want:  kafka.OffsetInvalid,
+		},
+		{
+			name:  "reassigned partition starts from its new offsets",
+			steps: []offsetStep{{"start", 0}, {"block", 0}, {"reset", 0}, {"start", 10}, {"done", 0}, {"done", 10}},
+			want:  11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newOffsetTracker()
+			for _, step := range tt.steps {
+				tp := testPartition(step.offset)
+				switch step.op {
+				case "start":
+					tracker.start(tp)
+				case "done":
+					tracker.markDone(tp)
+				case "block":
+					tracker.block(tp)
+				case "unblock":
+					tracker.unblock(tp)
+				case "reset":
+					tracker.reset([]kafka.TopicPartition{tp})
+				default:
+					t.Fatalf("unknown step %q", step.op)
+				}
+			}
+			if got := tracker.committableFor(testPartition(0)); got != tt.want {
+				t.Errorf("committable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOffsetTrackerWaitIdle(t *testing.T) {
+	tracker := newOffsetTracker()
+	tp := testPartition(0)
+	tracker.start(tp)
+	partitions := []kafka.TopicPartition{tp}
+
+	if tracker.waitIdle(partitions, 20*time.Millisecond) {
+		t.Fatal("waitIdle returned true with a message in flight")
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tracker.markDone(tp)
+	}()
+	if !tracker.waitIdle(partitions, time.Second) {
+		t.Fatal("waitIdle timed out after the message was done")
+	}
+}
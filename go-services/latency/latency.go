@@ -0,0 +1,126 @@
+// Package latency records latencies in HDR-style histograms: values are
+// rounded up to two significant digits, so every bucket is within 10% of the
+// values it holds while a histogram covering microseconds to minutes stays a
+// few hundred buckets wide.
+package latency
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Stages of the pipeline whose latency is measured.
+const (
+	// StagePublishToConsume goes from the gRPC server publishing a message
+	// to a consumer picking it up.
+	StagePublishToConsume = "publish_to_consume"
+	// StageReceiveToStored goes from the gRPC server receiving a tweet to a
+	// consumer having it stored in Valkey.
+	StageReceiveToStored = "receive_to_stored"
+)
+
+// Stages lists every measured stage.
+var Stages = []string{StagePublishToConsume, StageReceiveToStored}
+
+// Bucket returns the upper bound, in microseconds, of the bucket holding d.
+func Bucket(d time.Duration) int64 {
+	us := d.Microseconds()
+	if us < 100 {
+		if us < 1 {
+			return 1
+		}
+		return us
+	}
+	scale := int64(1)
+	for v := us; v >= 100; v /= 10 {
+		scale *= 10
+	}
+	return (us + scale - 1) / scale * scale
+}
+
+// Histogram counts observations per bucket upper bound in microseconds.
+type Histogram map[int64]int64
+
+// Record adds one observation.
+func (h Histogram) Record(d time.Duration) {
+	h[Bucket(d)]++
+}
+
+// Merge adds every observation of other to h.
+func (h Histogram) Merge(other Histogram) {
+	for bucket, n := range other {
+		h[bucket] += n
+	}
+}
+
+// Count returns the number of observations.
+func (h Histogram) Count() int64 {
+	var total int64
+	for _, n := range h {
+		total += n
+	}
+	return total
+}
+
+// Percentile returns the upper bound of the bucket holding the q-th
+// percentile (0-100), or 0 for an empty histogram.
+func (h Histogram) Percentile(q float64) time.Duration {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+	buckets := h.buckets()
+	rank := int64(math.Ceil(q / 100 * float64(total)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for _, b := range buckets {
+		seen += h[b]
+		if seen >= rank {
+			return time.Duration(b) * time.Microsecond
+		}
+	}
+	return time.Duration(buckets[len(buckets)-1]) * time.Microsecond
+}
+
+// Mean returns the average of the bucket upper bounds, weighted by count.
+func (h Histogram) Mean() time.Duration {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+	var sum float64
+	for b, n := range h {
+		sum += float64(b) * float64(n)
+	}
+	return time.Duration(sum/float64(total)) * time.Microsecond
+}
+
+func (h Histogram) buckets() []int64 {
+	buckets := make([]int64, 0, len(h))
+	for b := range h {
+		buckets = append(buckets, b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	return buckets
+}
+
+// FromStrings builds a histogram from the field/value pairs of a Valkey hash.
+func FromStrings(fields map[string]string) Histogram {
+	h := make(Histogram, len(fields))
+	for k, v := range fields {
+		bucket, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		h[bucket] += n
+	}
+	return h
+}
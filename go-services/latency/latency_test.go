@@ -0,0 +1,127 @@
+package latency
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int64
+	}{
+		{0, 1},
+		{500 * time.Nanosecond, 1},
+		{time.Microsecond, 1},
+		{99 * time.Microsecond, 99},
+		{100 * time.Microsecond, 100},
+		{101 * time.Microsecond, 110},
+		{999 * time.Microsecond, 1000},
+		{time.Millisecond, 1000},
+		{1001 * time.Microsecond, 1100},
+		{1234 * time.Millisecond, 1300000},
+		{time.Minute, 60000000},
+		// Nothing caps the buckets: durations past minutes keep two
+		// significant digits instead of piling up in a last bucket.
+		{time.Hour, 3600000000},
+		{61 * time.Minute, 3700000000},
+		{time.Duration(math.MaxInt64), 9300000000000000},
+	}
+	for _, tt := range tests {
+		if got := Bucket(tt.d); got != tt.want {
+			t.Errorf("Bucket(%v) = %d, want %d", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestBucketWithinTenPercent(t *testing.T) {
+	for us := int64(1); us < 100000000; us = us*11/10 + 1 {
+		b := Bucket(time.Duration(us) * time.Microsecond)
+		if b < us || b-us > us/10 {
+			t.Errorf("Bucket(%dµs) = %d, more than 10%% off", us, b)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	uniform := make(Histogram)
+	for i := 1; i <= 100; i++ {
+		uniform.Record(time.Duration(i) * time.Millisecond)
+	}
+	skewed := make(Histogram)
+	for i := 0; i < 90; i++ {
+		skewed.Record(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		skewed.Record(time.Second)
+	}
+	huge := make(Histogram)
+	huge.Record(time.Millisecond)
+	huge.Record(2 * time.Hour)
+
+	tests := []struct {
+		name string
+		h    Histogram
+		q    float64
+		want time.Duration
+	}{
+		{"uniform p0", uniform, 0, time.Millisecond},
+		{"uniform p50", uniform, 50, 50 * time.Millisecond},
+		{"uniform p95", uniform, 95, 95 * time.Millisecond},
+		{"uniform p99", uniform, 99, 99 * time.Millisecond},
+		{"uniform p100", uniform, 100, 100 * time.Millisecond},
+		{"skewed p50", skewed, 50, time.Millisecond},
+		{"skewed p90", skewed, 90, time.Millisecond},
+		{"skewed p91", skewed, 91, time.Second},
+		{"skewed p99", skewed, 99, time.Second},
+		{"above the largest bucket", huge, 99, 2 * time.Hour},
+		{"above 100", huge, 150, 2 * time.Hour},
+		{"empty", Histogram{}, 50, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.h.Percentile(tt.q); got != tt.want {
+			t.Errorf("%s: Percentile(%v) = %v, want %v", tt.name, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestMean(t *testing.T) {
+	uniform := make(Histogram)
+	for i := 1; i <= 100; i++ {
+		uniform.Record(time.Duration(i) * time.Millisecond)
+	}
+	skewed := Histogram{1000: 90, 1000000: 10}
+
+	tests := []struct {
+		name string
+		h    Histogram
+		want time.Duration
+	}{
+		{"uniform", uniform, 50500 * time.Microsecond},
+		{"skewed", skewed, 100900 * time.Microsecond},
+		{"empty", Histogram{}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.h.Mean(); got != tt.want {
+			t.Errorf("%s: Mean() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFromStringsMerge(t *testing.T) {
+	h := FromStrings(map[string]string{"1000": "3", "2000": "1", "bad": "4", "3000": "x"})
+	h.Merge(Histogram{1000: 1, 5000: 2})
+	want := Histogram{1000: 4, 2000: 1, 5000: 2}
+	if len(h) != len(want) {
+		t.Fatalf("histogram = %v, want %v", h, want)
+	}
+	for b, n := range want {
+		if h[b] != n {
+			t.Errorf("bucket %d = %d, want %d", b, h[b], n)
+		}
+	}
+	if h.Count() != 7 {
+		t.Errorf("Count() = %d, want 7", h.Count())
+	}
+}
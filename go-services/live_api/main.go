@@ -0,0 +1,196 @@
+// Command live_api streams the stored tweets as they are processed, over
+// Server-Sent Events on /tweets/stream and WebSocket on /tweets/ws.
+//
+// Both endpoints take the same query parameters:
+//
+//	municipality   comma-separated municipalities to keep; all by default
+//	condition      comma-separated weather conditions to keep; all by default
+//	sample         fraction of the tweets to send, between 0 and 1
+//	last_event_id  resume after this event; SSE clients may use the
+//	               Last-Event-ID header instead
+package main
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-services/storage"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// heartbeatInterval is how long a client waits without tweets before it
+	// gets a keep-alive, so proxies don't close idle connections.
+	heartbeatInterval = 15 * time.Second
+	readBatch         = 100
+)
+
+type server struct {
+	rdb *redis.Client
+	// clients limits the open streams, each of which holds a Valkey
+	// connection while it blocks on the stream.
+	clients chan struct{}
+	// upgrader checks the origin of WebSocket connections.
+	upgrader *websocket.Upgrader
+}
+
+// filter selects the tweets a client asked for.
+type filter struct {
+	municipalities map[string]bool
+	conditions     map[string]bool
+	sample         float64
+}
+
+// errBadEventID rejects resume positions that aren't stream entry IDs.
+var errBadEventID = errors.New("invalid last event ID")
+
+func parseFilter(r *http.Request) (filter, error) {
+	q := r.URL.Query()
+	f := filter{
+		municipalities: parseSet(q.Get("municipality")),
+		conditions:     parseSet(q.Get("condition")),
+		sample:         1,
+	}
+	if s := q.Get("sample"); s != "" {
+		sample, err := strconv.ParseFloat(s, 64)
+		if err != nil || sample <= 0 || sample > 1 {
+			return filter{}, errors.New("sample must be between 0 and 1")
+		}
+		f.sample = sample
+	}
+	return f, nil
+}
+
+func parseSet(list string) map[string]bool {
+	if list == "" {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, v := range strings.Split(list, ",") {
+		set[strings.TrimSpace(v)] = true
+	}
+	return set
+}
+
+// match reports whether t passes f. Sampling hashes the tweet ID, so a
+// client resuming with the same sample sees the same tweets.
+func (f filter) match(t storage.LiveTweet) bool {
+	if f.municipalities != nil && !f.municipalities[t.Municipality] {
+		return false
+	}
+	if f.conditions != nil && !f.conditions[t.Condition] {
+		return false
+	}
+	if f.sample < 1 {
+		h := fnv.New32a()
+		h.Write([]byte(t.ID))
+		return float64(h.Sum32()%10000) < f.sample*10000
+	}
+	return true
+}
+
+// eventIDPattern matches stream entry IDs.
+var eventIDPattern = regexp.MustCompile(`^[0-9]+(-[0-9]+)?$`)
+
+// startID returns the stream entry to read after: the one the client last
+// saw, or the newest one for clients starting fresh.
+func (s *server) startID(ctx context.Context, lastEventID string) (string, error) {
+	if lastEventID != "" {
+		if !eventIDPattern.MatchString(lastEventID) {
+			return "", errBadEventID
+		}
+		return lastEventID, nil
+	}
+	return storage.LastLiveID(ctx, s.rdb)
+}
+
+// follow reads the live stream after lastID until ctx is done or send fails,
+// passing on the tweets that match f and calling idle when no tweet arrived
+// for heartbeatInterval.
+func (s *server) follow(ctx context.Context, lastID string, f filter, send func(storage.LiveTweet) error, idle func() error) error {
+	for {
+		tweets, err := storage.ReadLive(ctx, s.rdb, lastID, readBatch, heartbeatInterval)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(tweets) == 0 {
+			if err := idle(); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, t := range tweets {
+			lastID = t.EventID
+			if !f.match(t) {
+				continue
+			}
+			if err := send(t); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// acquire reserves a client slot, answering the request itself when none is
+// left. The caller releases it by receiving from s.clients.
+func (s *server) acquire(w http.ResponseWriter) bool {
+	select {
+	case s.clients <- struct{}{}:
+		return true
+	default:
+		http.Error(w, "too many clients", http.StatusServiceUnavailable)
+		return false
+	}
+}
+
+func main() {
+	valkeyAddr := os.Getenv("VALKEY_ADDR")
+	if valkeyAddr == "" {
+		valkeyAddr = "valkey:6379"
+	}
+	addr := os.Getenv("HTTP_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	maxClients := storage.GetEnvInt("MAX_CLIENTS", 100)
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr: valkeyAddr,
+		// Every client blocks a connection; leave a few for the rest.
+		PoolSize: maxClients + 10,
+	})
+	s := &server{
+		rdb:      rdb,
+		clients:  make(chan struct{}, maxClients),
+		upgrader: newUpgrader(os.Getenv("ALLOWED_ORIGINS")),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tweets/stream", s.handleSSE)
+	mux.HandleFunc("/tweets/ws", s.handleWebSocket)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := rdb.Ping(r.Context()).Err(); err != nil {
+			http.Error(w, "valkey: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+
+	log.Printf("Live tweet stream listening at %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
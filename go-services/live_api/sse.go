@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"go-services/storage"
+)
+
+// handleSSE streams tweets as Server-Sent Events. Every event carries its
+// stream ID, which browsers send back in Last-Event-ID when they reconnect.
+func (s *server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	f, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if !s.acquire(w) {
+		return
+	}
+	defer func() { <-s.clients }()
+
+	ctx := r.Context()
+	lastID, err := s.startID(ctx, lastEventID)
+	if err == errBadEventID {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to read the live stream: %v", err)
+		http.Error(w, "failed to read the live stream", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Keep reverse proxies such as nginx from buffering the events.
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	send := func(t storage.LiveTweet) error {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %s\nevent: tweet\ndata: %s\n\n", t.EventID, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+	idle := func() error {
+		if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+	if err := s.follow(ctx, lastID, f, send, idle); err != nil {
+		log.Printf("SSE stream ended: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-services/storage"
+
+	"github.com/gorilla/websocket"
+)
+
+const writeTimeout = 10 * time.Second
+
+// newUpgrader accepts WebSocket connections from the page's own origin and
+// from allowed, a comma-separated list of origins such as the map demo's.
+// Requests without an Origin header don't come from a browser and are let
+// through, as gorilla's own check does.
+func newUpgrader(allowed string) *websocket.Upgrader {
+	origins := make(map[string]bool)
+	for _, o := range strings.Split(allowed, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins[o] = true
+		}
+	}
+	return &websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" || origins[origin] {
+				return true
+			}
+			u, err := url.Parse(origin)
+			return err == nil && strings.EqualFold(u.Host, r.Host)
+		},
+	}
+}
+
+// handleWebSocket streams tweets as JSON text messages. Each one carries its
+// event_id, which clients pass back as last_event_id when they reconnect.
+func (s *server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.acquire(w) {
+		return
+	}
+	defer func() { <-s.clients }()
+
+	lastID, err := s.startID(r.Context(), r.URL.Query().Get("last_event_id"))
+	if err == errBadEventID {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to read the live stream: %v", err)
+		http.Error(w, "failed to read the live stream", http.StatusBadGateway)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already answered the request.
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// The request context isn't cancelled for hijacked connections, so
+	// watch the connection instead. Clients aren't expected to send
+	// anything; reading also handles their pings and close frames.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(t storage.LiveTweet) error {
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		return conn.WriteJSON(t)
+	}
+	idle := func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+	}
+	if err := s.follow(ctx, lastID, f, send, idle); err != nil {
+		log.Printf("WebSocket stream ended: %v", err)
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeTimeout))
+}
@@ -0,0 +1,228 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/streadway/amqp"
+)
+
+// Tweets are published as CloudEvents 1.0 (https://cloudevents.io). The
+// event data is the JSON of the WeatherTweetRequest and the envelope fields
+// travel as attributes: ID as id, EventTime as time, Producer in source and
+// the rest as the extensions receivedat, clientid and schemaversion.
+const (
+	CloudEventsSpecVersion = "1.0"
+	TweetEventType         = "weather.tweet.received"
+	// CloudEventsContentType marks a structured mode message, whose body
+	// is the whole event as JSON.
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// Prefixes of the attributes in binary mode.
+	KafkaCloudEventsPrefix = "ce_"
+	AMQPCloudEventsPrefix  = "cloudEvents:"
+
+	// sourcePrefix is followed by the producer in the source attribute.
+	sourcePrefix = "/weather-tweets/grpc-server/"
+)
+
+// Message formats the gRPC server can publish in, chosen with
+// MESSAGE_FORMAT.
+const (
+	FormatBinary     = "binary"
+	FormatStructured = "structured"
+	// FormatEnvelope is the JSON Envelope, used before CloudEvents.
+	FormatEnvelope = "envelope"
+)
+
+// MessageFormat returns the format set in MESSAGE_FORMAT, binary mode
+// CloudEvents by default.
+func MessageFormat() string {
+	switch format := os.Getenv("MESSAGE_FORMAT"); format {
+	case FormatBinary, FormatStructured, FormatEnvelope:
+		return format
+	case "":
+		return FormatBinary
+	default:
+		log.Printf("Invalid value for MESSAGE_FORMAT (%q), using %s", format, FormatBinary)
+		return FormatBinary
+	}
+}
+
+// CloudEventAttributes returns the attributes of e as a CloudEvent, with the
+// string encoding binary mode uses.
+func CloudEventAttributes(e Envelope) map[string]string {
+	attrs := map[string]string{
+		"specversion":     CloudEventsSpecVersion,
+		"id":              e.ID,
+		"source":          sourcePrefix + e.Producer,
+		"type":            TweetEventType,
+		"datacontenttype": "application/json",
+		"schemaversion":   strconv.Itoa(e.SchemaVersion),
+	}
+	if e.EventTime > 0 {
+		attrs["time"] = formatTime(e.EventTime)
+	}
+	if e.ReceivedAt > 0 {
+		attrs["receivedat"] = formatTime(e.ReceivedAt)
+	}
+	if e.ClientID != "" {
+		attrs["clientid"] = e.ClientID
+	}
+	return attrs
+}
+
+// KafkaCloudEventHeaders returns the headers of e in Kafka binary mode; the
+// message value is e.Payload.
+func KafkaCloudEventHeaders(e Envelope) []kafka.Header {
+	var headers []kafka.Header
+	for name, value := range CloudEventAttributes(e) {
+		if name == "datacontenttype" {
+			headers = append(headers, kafka.Header{Key: "content-type", Value: []byte(value)})
+			continue
+		}
+		headers = append(headers, kafka.Header{Key: KafkaCloudEventsPrefix + name, Value: []byte(value)})
+	}
+	return headers
+}
+
+// AMQPCloudEventHeaders returns the application properties of e in AMQP
+// binary mode; the content type goes in the message property and the body
+// is e.Payload.
+func AMQPCloudEventHeaders(e Envelope) amqp.Table {
+	headers := amqp.Table{}
+	for name, value := range CloudEventAttributes(e) {
+		if name == "datacontenttype" {
+			continue
+		}
+		headers[AMQPCloudEventsPrefix+name] = value
+	}
+	return headers
+}
+
+// EncodeStructured returns e as a structured mode CloudEvent.
+func EncodeStructured(e Envelope) ([]byte, error) {
+	event := make(map[string]interface{})
+	for name, value := range CloudEventAttributes(e) {
+		event[name] = value
+	}
+	event["schemaversion"] = e.SchemaVersion
+	event["data"] = e.Payload
+	return json.Marshal(event)
+}
+
+// DecodeKafka reads a message in any format: binary mode CloudEvents, or a
+// body Decode understands.
+func DecodeKafka(msg *kafka.Message) (Envelope, error) {
+	attrs := make(map[string]string)
+	for _, h := range msg.Headers {
+		if name, ok := strings.CutPrefix(h.Key, KafkaCloudEventsPrefix); ok {
+			attrs[name] = string(h.Value)
+		}
+	}
+	if attrs["specversion"] == "" {
+		return Decode(msg.Value)
+	}
+	return fromCloudEvent(attrs, msg.Value)
+}
+
+// DecodeAMQP reads a delivery in any format: binary mode CloudEvents, or a
+// body Decode understands. The cloudEvents_ prefix of the JMS mapping is
+// accepted too.
+func DecodeAMQP(d amqp.Delivery) (Envelope, error) {
+	attrs := make(map[string]string)
+	for key, value := range d.Headers {
+		name, ok := strings.CutPrefix(key, AMQPCloudEventsPrefix)
+		if !ok {
+			name, ok = strings.CutPrefix(key, "cloudEvents_")
+		}
+		if ok {
+			attrs[name] = fmt.Sprint(value)
+		}
+	}
+	if attrs["specversion"] == "" {
+		return Decode(d.Body)
+	}
+	return fromCloudEvent(attrs, d.Body)
+}
+
+// decodeStructured reads a structured mode CloudEvent.
+func decodeStructured(body []byte) (Envelope, error) {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Envelope{}, err
+	}
+	attrs := make(map[string]string, len(event))
+	for name, raw := range event {
+		if name == "data" {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			// Numbers and booleans keep their JSON text.
+			s = string(raw)
+		}
+		attrs[name] = s
+	}
+	return fromCloudEvent(attrs, event["data"])
+}
+
+func fromCloudEvent(attrs map[string]string, data []byte) (Envelope, error) {
+	if attrs["specversion"] != CloudEventsSpecVersion {
+		return Envelope{}, fmt.Errorf("unsupported CloudEvents version %q", attrs["specversion"])
+	}
+	if attrs["type"] != TweetEventType {
+		return Envelope{}, fmt.Errorf("unexpected event type %q", attrs["type"])
+	}
+	e := Envelope{
+		SchemaVersion: SchemaVersion,
+		ID:            attrs["id"],
+		Producer:      strings.TrimPrefix(attrs["source"], sourcePrefix),
+		ClientID:      attrs["clientid"],
+		Payload:       data,
+	}
+	if v := attrs["schemaversion"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Envelope{}, fmt.Errorf("invalid schemaversion %q", v)
+		}
+		e.SchemaVersion = n
+	}
+	if e.SchemaVersion > SchemaVersion {
+		return Envelope{}, fmt.Errorf("%w %d", ErrUnsupportedSchema, e.SchemaVersion)
+	}
+	if err := e.validate(); err != nil {
+		return Envelope{}, err
+	}
+	var err error
+	if e.EventTime, err = parseTime(attrs["time"]); err != nil {
+		return Envelope{}, err
+	}
+	if e.ReceivedAt, err = parseTime(attrs["receivedat"]); err != nil {
+		return Envelope{}, err
+	}
+	return e, nil
+}
+
+func formatTime(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339Nano)
+}
+
+// parseTime returns an RFC 3339 timestamp in Unix milliseconds, zero if it
+// is missing.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.UnixMilli(), nil
+}
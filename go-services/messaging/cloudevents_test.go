@@ -0,0 +1,178 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/streadway/amqp"
+)
+
+func TestCloudEventRoundTrip(t *testing.T) {
+	env := testEnvelope()
+	structured, err := EncodeStructured(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enveloped, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		decode func() (Envelope, error)
+	}{
+		{
+			name: "Kafka binary",
+			decode: func() (Envelope, error) {
+				return DecodeKafka(&kafka.Message{Value: env.Payload, Headers: KafkaCloudEventHeaders(env)})
+			},
+		},
+		{
+			name: "AMQP binary",
+			decode: func() (Envelope, error) {
+				return DecodeAMQP(amqp.Delivery{Body: env.Payload, Headers: AMQPCloudEventHeaders(env)})
+			},
+		},
+		{
+			name: "AMQP binary with the JMS prefix",
+			decode: func() (Envelope, error) {
+				headers := amqp.Table{}
+				for name, value := range AMQPCloudEventHeaders(env) {
+					headers["cloudEvents_"+name[len(AMQPCloudEventsPrefix):]] = value
+				}
+				return DecodeAMQP(amqp.Delivery{Body: env.Payload, Headers: headers})
+			},
+		},
+		{
+			name: "Kafka structured",
+			decode: func() (Envelope, error) {
+				return DecodeKafka(&kafka.Message{Value: structured})
+			},
+		},
+		{
+			name: "AMQP structured",
+			decode: func() (Envelope, error) {
+				return DecodeAMQP(amqp.Delivery{Body: structured, ContentType: CloudEventsContentType})
+			},
+		},
+		{
+			name: "Kafka envelope",
+			decode: func() (Envelope, error) {
+				return DecodeKafka(&kafka.Message{Value: enveloped})
+			},
+		},
+		{
+			name: "AMQP envelope",
+			decode: func() (Envelope, error) {
+				return DecodeAMQP(amqp.Delivery{Body: enveloped})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.decode()
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			assertEnvelope(t, got, env)
+		})
+	}
+}
+
+func TestKafkaCloudEventHeaders(t *testing.T) {
+	headers := make(map[string]string)
+	for _, h := range KafkaCloudEventHeaders(testEnvelope()) {
+		headers[h.Key] = string(h.Value)
+	}
+	want := map[string]string{
+		"ce_specversion":   "1.0",
+		"ce_id":            "t1",
+		"ce_source":        "/weather-tweets/grpc-server/grpc-server-0",
+		"ce_type":          TweetEventType,
+		"ce_time":          "2023-11-14T22:13:20.123Z",
+		"ce_receivedat":    "2023-11-14T22:13:20.123Z",
+		"ce_clientid":      "10.0.0.7:5123",
+		"ce_schemaversion": "1",
+		"content-type":     "application/json",
+	}
+	if len(headers) != len(want) {
+		t.Errorf("headers = %v, want %v", headers, want)
+	}
+	for key, value := range want {
+		if headers[key] != value {
+			t.Errorf("header %s = %q, want %q", key, headers[key], value)
+		}
+	}
+}
+
+func TestAMQPCloudEventHeaders(t *testing.T) {
+	headers := AMQPCloudEventHeaders(testEnvelope())
+	want := map[string]string{
+		"cloudEvents:specversion":   "1.0",
+		"cloudEvents:id":            "t1",
+		"cloudEvents:source":        "/weather-tweets/grpc-server/grpc-server-0",
+		"cloudEvents:type":          TweetEventType,
+		"cloudEvents:time":          "2023-11-14T22:13:20.123Z",
+		"cloudEvents:receivedat":    "2023-11-14T22:13:20.123Z",
+		"cloudEvents:clientid":      "10.0.0.7:5123",
+		"cloudEvents:schemaversion": "1",
+	}
+	if len(headers) != len(want) {
+		t.Errorf("headers = %v, want %v", headers, want)
+	}
+	for key, value := range want {
+		if headers[key] != value {
+			t.Errorf("header %s = %v, want %q", key, headers[key], value)
+		}
+	}
+}
+
+func TestCloudEventRejects(t *testing.T) {
+	binary := func(mutate func(map[string]string), body string) func() (Envelope, error) {
+		return func() (Envelope, error) {
+			attrs := CloudEventAttributes(testEnvelope())
+			mutate(attrs)
+			var headers []kafka.Header
+			for name, value := range attrs {
+				headers = append(headers, kafka.Header{Key: KafkaCloudEventsPrefix + name, Value: []byte(value)})
+			}
+			return DecodeKafka(&kafka.Message{Value: []byte(body), Headers: headers})
+		}
+	}
+	structured := func(body string) func() (Envelope, error) {
+		return func() (Envelope, error) { return Decode([]byte(body)) }
+	}
+
+	tests := []struct {
+		name   string
+		decode func() (Envelope, error)
+		want   error
+	}{
+		{name: "binary with empty body", decode: binary(func(map[string]string) {}, ``), want: ErrInvalidPayload},
+		{name: "binary with null body", decode: binary(func(map[string]string) {}, `null`), want: ErrInvalidPayload},
+		{name: "binary with empty object", decode: binary(func(map[string]string) {}, `{}`), want: ErrInvalidPayload},
+		{name: "binary without id", decode: binary(func(a map[string]string) { delete(a, "id") }, testPayload), want: ErrInvalidPayload},
+		{name: "binary with other spec version", decode: binary(func(a map[string]string) { a["specversion"] = "0.3" }, testPayload)},
+		{name: "binary with other type", decode: binary(func(a map[string]string) { a["type"] = "weather.other" }, testPayload)},
+		{name: "binary with bad time", decode: binary(func(a map[string]string) { a["time"] = "yesterday" }, testPayload)},
+		{name: "binary with newer schema", decode: binary(func(a map[string]string) { a["schemaversion"] = "2" }, testPayload), want: ErrUnsupportedSchema},
+		{name: "structured without data", decode: structured(`{"specversion":"1.0","id":"t1","type":"weather.tweet.received"}`), want: ErrInvalidPayload},
+		{name: "structured with null data", decode: structured(`{"specversion":"1.0","id":"t1","type":"weather.tweet.received","data":null}`), want: ErrInvalidPayload},
+		{name: "structured with empty data", decode: structured(`{"specversion":"1.0","id":"t1","type":"weather.tweet.received","data":{}}`), want: ErrInvalidPayload},
+		{name: "structured with other type", decode: structured(`{"specversion":"1.0","id":"t1","type":"weather.other","data":` + testPayload + `}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.decode()
+			if err == nil {
+				t.Fatal("decode succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
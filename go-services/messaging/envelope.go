@@ -0,0 +1,113 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SchemaVersion is the envelope version the gRPC server publishes.
+// Consumers accept this version and older ones.
+const SchemaVersion = 1
+
+// Envelope wraps every message published to the brokers.
+type Envelope struct {
+	SchemaVersion int    `json:"schema_version"`
+	ID            string `json:"id"`
+	// EventTime is when the tweet happened (Unix milliseconds). Clients
+	// don't report it yet, so it is the time the gRPC server received it.
+	EventTime  int64 `json:"event_time"`
+	ReceivedAt int64 `json:"received_at"`
+	// Producer is the pod of the gRPC server that published the message.
+	Producer string `json:"producer"`
+	// ClientID identifies who sent the tweet to the gRPC server.
+	ClientID string `json:"client_id"`
+	// Payload is the JSON of the WeatherTweetRequest.
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ErrUnsupportedSchema is returned for envelopes newer than this build.
+var ErrUnsupportedSchema = errors.New("unsupported schema version")
+
+// ErrInvalidPayload is returned for messages that don't carry a tweet.
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// tweetFields are the JSON fields of a WeatherTweetRequest. Each is omitted
+// when zero, so none is required on its own, but a tweet has at least one.
+var tweetFields = []string{"municipality", "temperature", "humidity", "weather", "id", "received_at"}
+
+// Producer returns the name published as Envelope.Producer: POD_NAME, or
+// the hostname outside Kubernetes.
+func Producer() string {
+	if pod := os.Getenv("POD_NAME"); pod != "" {
+		return pod
+	}
+	host, _ := os.Hostname()
+	return host
+}
+
+// Decode reads a message body: a structured mode CloudEvent or an Envelope.
+// Bodies published before the envelope, the bare JSON of a
+// WeatherTweetRequest, and binary mode CloudEvents read without their
+// attributes are returned as an envelope of version 0 with the ID and
+// received_at found in the payload.
+func Decode(body []byte) (Envelope, error) {
+	var probe struct {
+		SpecVersion string `json:"specversion"`
+	}
+	if err := json.Unmarshal(body, &probe); err == nil && probe.SpecVersion != "" {
+		return decodeStructured(body)
+	}
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return Envelope{}, err
+	}
+	if env.SchemaVersion == 0 && env.Payload == nil {
+		// A bare tweet shares the id and received_at fields.
+		if err := validatePayload(body); err != nil {
+			return Envelope{}, err
+		}
+		return Envelope{
+			ID:         env.ID,
+			EventTime:  env.ReceivedAt,
+			ReceivedAt: env.ReceivedAt,
+			Payload:    body,
+		}, nil
+	}
+	if env.SchemaVersion > SchemaVersion {
+		return Envelope{}, fmt.Errorf("%w %d", ErrUnsupportedSchema, env.SchemaVersion)
+	}
+	if err := env.validate(); err != nil {
+		return Envelope{}, err
+	}
+	return env, nil
+}
+
+// validate checks that an envelope has the ID the gRPC server always sets
+// and a tweet as payload.
+func (e Envelope) validate() error {
+	if e.ID == "" {
+		return fmt.Errorf("%w: envelope without id", ErrInvalidPayload)
+	}
+	return validatePayload(e.Payload)
+}
+
+// validatePayload checks that payload is a JSON object with at least one
+// tweet field, so empty bodies are dead-lettered instead of counted as
+// unknown tweets.
+func validatePayload(payload []byte) error {
+	if len(payload) == 0 {
+		return fmt.Errorf("%w: empty", ErrInvalidPayload)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	for _, name := range tweetFields {
+		if _, ok := fields[name]; ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no tweet fields", ErrInvalidPayload)
+}
@@ -0,0 +1,112 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const testPayload = `{"municipality":1,"temperature":25,"humidity":60,"weather":1,"id":"t1","received_at":1700000000123}`
+
+func testEnvelope() Envelope {
+	return Envelope{
+		SchemaVersion: SchemaVersion,
+		ID:            "t1",
+		EventTime:     1700000000123,
+		ReceivedAt:    1700000000123,
+		Producer:      "grpc-server-0",
+		ClientID:      "10.0.0.7:5123",
+		Payload:       json.RawMessage(testPayload),
+	}
+}
+
+func TestDecode(t *testing.T) {
+	env := testEnvelope()
+	enveloped, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want Envelope
+	}{
+		{
+			name: "bare tweet",
+			body: testPayload,
+			want: Envelope{ID: "t1", EventTime: 1700000000123, ReceivedAt: 1700000000123, Payload: json.RawMessage(testPayload)},
+		},
+		{
+			name: "bare tweet without id",
+			body: `{"municipality":2,"weather":3}`,
+			want: Envelope{Payload: json.RawMessage(`{"municipality":2,"weather":3}`)},
+		},
+		{
+			name: "envelope v1",
+			body: string(enveloped),
+			want: env,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			assertEnvelope(t, got, tt.want)
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{name: "empty body", body: ``},
+		{name: "not JSON", body: `weather`},
+		{name: "null", body: `null`, want: ErrInvalidPayload},
+		{name: "empty object", body: `{}`, want: ErrInvalidPayload},
+		{name: "array", body: `[]`},
+		{name: "no tweet fields", body: `{"foo":1}`, want: ErrInvalidPayload},
+		{name: "newer schema", body: `{"schema_version":2,"id":"t1","payload":` + testPayload + `}`, want: ErrUnsupportedSchema},
+		{name: "envelope without payload", body: `{"schema_version":1,"id":"t1"}`, want: ErrInvalidPayload},
+		{name: "envelope with null payload", body: `{"schema_version":1,"id":"t1","payload":null}`, want: ErrInvalidPayload},
+		{name: "envelope with empty payload", body: `{"schema_version":1,"id":"t1","payload":{}}`, want: ErrInvalidPayload},
+		{name: "envelope without id", body: `{"schema_version":1,"payload":` + testPayload + `}`, want: ErrInvalidPayload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.body))
+			if err == nil {
+				t.Fatal("Decode succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func assertEnvelope(t *testing.T, got, want Envelope) {
+	t.Helper()
+	if got.SchemaVersion != want.SchemaVersion || got.ID != want.ID || got.EventTime != want.EventTime ||
+		got.ReceivedAt != want.ReceivedAt || got.Producer != want.Producer || got.ClientID != want.ClientID {
+		t.Errorf("envelope = %+v, want %+v", got, want)
+	}
+	if !jsonEqual(got.Payload, want.Payload) {
+		t.Errorf("payload = %s, want %s", got.Payload, want.Payload)
+	}
+}
+
+func jsonEqual(a, b []byte) bool {
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(x)
+	jb, _ := json.Marshal(y)
+	return string(ja) == string(jb)
+}
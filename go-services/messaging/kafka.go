@@ -0,0 +1,94 @@
+package messaging
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	// TweetsTopic is the Kafka topic the gRPC server produces to.
+	TweetsTopic = "weather-tweets"
+	// DeadLetterTopic keeps records the Kafka consumer could not process.
+	DeadLetterTopic = "weather-tweets.dlq"
+)
+
+// HeaderTweetID carries the tweet ID on Kafka records. RabbitMQ messages use
+// the message-id property instead.
+const HeaderTweetID = "x-tweet-id"
+
+// Timestamps, in Unix milliseconds, stamped by the gRPC server on messages of
+// both brokers to measure latency.
+const (
+	HeaderReceivedAt  = "x-received-at"
+	HeaderPublishedAt = "x-published-at"
+)
+
+// HeaderInt64 parses a decimal header, returning false if it is missing or
+// malformed.
+func HeaderInt64(msg *kafka.Message, key string) (int64, bool) {
+	value := Header(msg, key)
+	if value == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(string(value), 10, 64)
+	return n, err == nil
+}
+
+// Header returns the value of the first header named key, or nil.
+func Header(msg *kafka.Message, key string) []byte {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return h.Value
+		}
+	}
+	return nil
+}
+
+// Headers attached to dead-lettered Kafka records pointing back at the
+// original record.
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+)
+
+// ReasonStorageFailed marks records that could not be stored in Valkey within
+// the retry budget.
+const ReasonStorageFailed = "storage-failed"
+
+// DeadLetterKafka produces a copy of msg to DeadLetterTopic with headers
+// describing the failure and waits for the broker to acknowledge it.
+func DeadLetterKafka(p *kafka.Producer, msg *kafka.Message, reason string, cause error, attempts int) error {
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(*msg.TopicPartition.Topic)},
+		kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(int(msg.TopicPartition.Partition)))},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(msg.TopicPartition.Offset.String())},
+		kafka.Header{Key: HeaderFailureReason, Value: []byte(reason)},
+		kafka.Header{Key: HeaderFailureAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+	if cause != nil {
+		headers = append(headers, kafka.Header{Key: HeaderFailureError, Value: []byte(cause.Error())})
+	}
+
+	topic := DeadLetterTopic
+	delivery := make(chan kafka.Event, 1)
+	err := p.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            msg.Key,
+		Value:          msg.Value,
+		Headers:        headers,
+	}, delivery)
+	if err != nil {
+		return err
+	}
+
+	e := <-delivery
+	if m, ok := e.(*kafka.Message); ok {
+		return m.TopicPartition.Error
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package messaging holds the broker topology shared by the gRPC server and
+// the consumers, so both sides declare queues and topics with the same
+// arguments.
+package messaging
+
+import (
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const (
+	// TweetsQueue is the RabbitMQ queue the gRPC server publishes to.
+	TweetsQueue = "weather-tweets"
+	// DeadLetterExchange receives tweets that could not be processed.
+	DeadLetterExchange = "weather-tweets.dlx"
+	// DeadLetterQueue is bound to DeadLetterExchange and keeps failed tweets
+	// until they are inspected or replayed.
+	DeadLetterQueue = "weather-tweets.dlq"
+
+	// MaxDeliveries is how many times a message may be delivered before it
+	// is dead-lettered. It is part of the queue arguments, which must be the
+	// same in every declaration, so it can't be configured per process.
+	MaxDeliveries = 5
+)
+
+// Headers attached to dead-lettered messages describing why they failed.
+const (
+	HeaderFailureReason   = "x-failure-reason"
+	HeaderFailureError    = "x-failure-error"
+	HeaderFailureAttempts = "x-failure-attempts"
+	HeaderFailedAt        = "x-failed-at"
+	HeaderOriginalQueue   = "x-original-queue"
+)
+
+// Failure reasons stored in HeaderFailureReason.
+const (
+	ReasonMalformed     = "malformed-body"
+	ReasonMaxDeliveries = "max-deliveries"
+)
+
+// TweetsQueueArgs returns the arguments of the tweets queue. RabbitMQ
+// rejects a declaration whose arguments differ from the existing queue's, so
+// every process declares it with these.
+func TweetsQueueArgs() amqp.Table {
+	return amqp.Table{
+		"x-queue-type":           "quorum",
+		"x-delivery-limit":       int32(MaxDeliveries),
+		"x-dead-letter-exchange": DeadLetterExchange,
+	}
+}
+
+// DeclareRabbitMQ declares the tweets queue together with its dead-letter
+// exchange and queue. The tweets queue is a quorum queue so the broker itself
+// enforces the delivery limit, even if a message crashes the consumer.
+//
+// Queue arguments can't be changed on an existing queue: a classic
+// weather-tweets queue left over from older deployments makes this fail until
+// it is converted with "weatherctl migrate-queue".
+func DeclareRabbitMQ(ch *amqp.Channel) (amqp.Queue, error) {
+	err := ch.ExchangeDeclare(
+		DeadLetterExchange, // name
+		"fanout",           // type
+		true,               // durable
+		false,              // auto-deleted
+		false,              // internal
+		false,              // no-wait
+		nil,                // arguments
+	)
+	if err != nil {
+		return amqp.Queue{}, err
+	}
+
+	dlq, err := ch.QueueDeclare(
+		DeadLetterQueue, // name
+		true,            // durable
+		false,           // delete when unused
+		false,           // exclusive
+		false,           // no-wait
+		nil,             // arguments
+	)
+	if err != nil {
+		return amqp.Queue{}, err
+	}
+
+	err = ch.QueueBind(
+		dlq.Name,           // queue name
+		"",                 // routing key
+		DeadLetterExchange, // exchange
+		false,              // no-wait
+		nil,                // arguments
+	)
+	if err != nil {
+		return amqp.Queue{}, err
+	}
+
+	return ch.QueueDeclare(
+		TweetsQueue,       // name
+		true,              // durable
+		false,             // delete when unused
+		false,             // exclusive
+		false,             // no-wait
+		TweetsQueueArgs(), // arguments
+	)
+}
+
+// DeadLetter publishes a copy of d to the dead-letter exchange with headers
+// describing the failure. The caller still has to settle d.
+func DeadLetter(ch *amqp.Channel, d amqp.Delivery, reason string, cause error, attempts int64) error {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[HeaderFailureReason] = reason
+	if cause != nil {
+		headers[HeaderFailureError] = cause.Error()
+	}
+	headers[HeaderFailureAttempts] = attempts
+	headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339)
+	headers[HeaderOriginalQueue] = TweetsQueue
+
+	return ch.Publish(
+		DeadLetterExchange, // exchange
+		"",                 // routing key
+		false,              // mandatory
+		false,              // immediate
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Timestamp:    d.Timestamp,
+			Body:         d.Body,
+		})
+}
@@ -0,0 +1,106 @@
+package messaging
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/streadway/amqp"
+)
+
+// Tweet is the payload of a message, the JSON of a WeatherTweetRequest.
+type Tweet struct {
+	Municipality int32  `json:"municipality"`
+	Temperature  int32  `json:"temperature"`
+	Humidity     int32  `json:"humidity"`
+	Weather      int32  `json:"weather"`
+	ID           string `json:"id"`
+	ReceivedAt   int64  `json:"received_at"`
+}
+
+// DecodeKafkaTweet reads the tweet of a Kafka message in any format.
+func DecodeKafkaTweet(msg *kafka.Message) (Tweet, error) {
+	env, err := DecodeKafka(msg)
+	if err != nil {
+		return Tweet{}, err
+	}
+	return env.tweet()
+}
+
+// DecodeAMQPTweet reads the tweet of a RabbitMQ delivery in any format.
+func DecodeAMQPTweet(d amqp.Delivery) (Tweet, error) {
+	env, err := DecodeAMQP(d)
+	if err != nil {
+		return Tweet{}, err
+	}
+	return env.tweet()
+}
+
+// tweet unmarshals the payload, taking the ID and receive time from the
+// envelope when the payload has none.
+func (e Envelope) tweet() (Tweet, error) {
+	var t Tweet
+	if err := json.Unmarshal(e.Payload, &t); err != nil {
+		return Tweet{}, err
+	}
+	if t.ID == "" {
+		t.ID = e.ID
+	}
+	if t.ReceivedAt == 0 {
+		t.ReceivedAt = e.ReceivedAt
+	}
+	return t, nil
+}
+
+// OrderingKey returns the worker pool key of a decoded tweet: its
+// municipality, so tweets from the same municipality are stored in order.
+// Messages that don't decode share one key; consumers pass the decode error
+// on to the worker, which dead-letters them.
+func OrderingKey(t Tweet, err error) string {
+	if err != nil {
+		return ""
+	}
+	return strconv.Itoa(int(t.Municipality))
+}
+
+// MunicipalityName returns the name the municipality is stored under.
+func (t Tweet) MunicipalityName() string {
+	switch t.Municipality {
+	case 1:
+		return "mixco"
+	case 2:
+		return "guatemala"
+	case 3:
+		return "amatitlan"
+	case 4:
+		return "chinautla"
+	default:
+		return "unknown"
+	}
+}
+
+// ConditionName returns the name the weather condition is stored under.
+func (t Tweet) ConditionName() string {
+	switch t.Weather {
+	case 1:
+		return "sunny"
+	case 2:
+		return "cloudy"
+	case 3:
+		return "rainy"
+	case 4:
+		return "foggy"
+	default:
+		return "unknown"
+	}
+}
+
+// ReceivedTime returns when the gRPC server received the tweet, zero if
+// unknown.
+func (t Tweet) ReceivedTime() time.Time {
+	if t.ReceivedAt <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(t.ReceivedAt)
+}
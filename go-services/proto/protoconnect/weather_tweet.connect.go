@@ -0,0 +1,300 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: proto/weather_tweet.proto
+
+package protoconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	proto "go-services/proto"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// WeatherTweetServiceName is the fully-qualified name of the WeatherTweetService service.
+	WeatherTweetServiceName = "wethertweet.WeatherTweetService"
+	// WeatherStatsServiceName is the fully-qualified name of the WeatherStatsService service.
+	WeatherStatsServiceName = "wethertweet.WeatherStatsService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// WeatherTweetServiceSendTweetProcedure is the fully-qualified name of the WeatherTweetService's
+	// SendTweet RPC.
+	WeatherTweetServiceSendTweetProcedure = "/wethertweet.WeatherTweetService/SendTweet"
+	// WeatherStatsServiceGetConditionCountsProcedure is the fully-qualified name of the
+	// WeatherStatsService's GetConditionCounts RPC.
+	WeatherStatsServiceGetConditionCountsProcedure = "/wethertweet.WeatherStatsService/GetConditionCounts"
+	// WeatherStatsServiceGetMunicipalityStatsProcedure is the fully-qualified name of the
+	// WeatherStatsService's GetMunicipalityStats RPC.
+	WeatherStatsServiceGetMunicipalityStatsProcedure = "/wethertweet.WeatherStatsService/GetMunicipalityStats"
+	// WeatherStatsServiceGetTimeSeriesProcedure is the fully-qualified name of the
+	// WeatherStatsService's GetTimeSeries RPC.
+	WeatherStatsServiceGetTimeSeriesProcedure = "/wethertweet.WeatherStatsService/GetTimeSeries"
+	// WeatherStatsServiceWatchStatsProcedure is the fully-qualified name of the WeatherStatsService's
+	// WatchStats RPC.
+	WeatherStatsServiceWatchStatsProcedure = "/wethertweet.WeatherStatsService/WatchStats"
+	// WeatherStatsServiceListRecentTweetsProcedure is the fully-qualified name of the
+	// WeatherStatsService's ListRecentTweets RPC.
+	WeatherStatsServiceListRecentTweetsProcedure = "/wethertweet.WeatherStatsService/ListRecentTweets"
+)
+
+// WeatherTweetServiceClient is a client for the wethertweet.WeatherTweetService service.
+type WeatherTweetServiceClient interface {
+	SendTweet(context.Context, *connect.Request[proto.WeatherTweetRequest]) (*connect.Response[proto.WeatherTweetResponse], error)
+}
+
+// NewWeatherTweetServiceClient constructs a client for the wethertweet.WeatherTweetService service.
+// By default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped
+// responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewWeatherTweetServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) WeatherTweetServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	weatherTweetServiceMethods := proto.File_proto_weather_tweet_proto.Services().ByName("WeatherTweetService").Methods()
+	return &weatherTweetServiceClient{
+		sendTweet: connect.NewClient[proto.WeatherTweetRequest, proto.WeatherTweetResponse](
+			httpClient,
+			baseURL+WeatherTweetServiceSendTweetProcedure,
+			connect.WithSchema(weatherTweetServiceMethods.ByName("SendTweet")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// weatherTweetServiceClient implements WeatherTweetServiceClient.
+type weatherTweetServiceClient struct {
+	sendTweet *connect.Client[proto.WeatherTweetRequest, proto.WeatherTweetResponse]
+}
+
+// SendTweet calls wethertweet.WeatherTweetService.SendTweet.
+func (c *weatherTweetServiceClient) SendTweet(ctx context.Context, req *connect.Request[proto.WeatherTweetRequest]) (*connect.Response[proto.WeatherTweetResponse], error) {
+	return c.sendTweet.CallUnary(ctx, req)
+}
+
+// WeatherTweetServiceHandler is an implementation of the wethertweet.WeatherTweetService service.
+type WeatherTweetServiceHandler interface {
+	SendTweet(context.Context, *connect.Request[proto.WeatherTweetRequest]) (*connect.Response[proto.WeatherTweetResponse], error)
+}
+
+// NewWeatherTweetServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewWeatherTweetServiceHandler(svc WeatherTweetServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	weatherTweetServiceMethods := proto.File_proto_weather_tweet_proto.Services().ByName("WeatherTweetService").Methods()
+	weatherTweetServiceSendTweetHandler := connect.NewUnaryHandler(
+		WeatherTweetServiceSendTweetProcedure,
+		svc.SendTweet,
+		connect.WithSchema(weatherTweetServiceMethods.ByName("SendTweet")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/wethertweet.WeatherTweetService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case WeatherTweetServiceSendTweetProcedure:
+			weatherTweetServiceSendTweetHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedWeatherTweetServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedWeatherTweetServiceHandler struct{}
+
+func (UnimplementedWeatherTweetServiceHandler) SendTweet(context.Context, *connect.Request[proto.WeatherTweetRequest]) (*connect.Response[proto.WeatherTweetResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("wethertweet.WeatherTweetService.SendTweet is not implemented"))
+}
+
+// WeatherStatsServiceClient is a client for the wethertweet.WeatherStatsService service.
+type WeatherStatsServiceClient interface {
+	GetConditionCounts(context.Context, *connect.Request[proto.ConditionCountsRequest]) (*connect.Response[proto.ConditionCountsResponse], error)
+	GetMunicipalityStats(context.Context, *connect.Request[proto.MunicipalityStatsRequest]) (*connect.Response[proto.MunicipalityStatsResponse], error)
+	GetTimeSeries(context.Context, *connect.Request[proto.TimeSeriesRequest]) (*connect.Response[proto.TimeSeriesResponse], error)
+	WatchStats(context.Context, *connect.Request[proto.WatchStatsRequest]) (*connect.ServerStreamForClient[proto.StatsUpdate], error)
+	ListRecentTweets(context.Context, *connect.Request[proto.ListRecentTweetsRequest]) (*connect.Response[proto.ListRecentTweetsResponse], error)
+}
+
+// NewWeatherStatsServiceClient constructs a client for the wethertweet.WeatherStatsService service.
+// By default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped
+// responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewWeatherStatsServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) WeatherStatsServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	weatherStatsServiceMethods := proto.File_proto_weather_tweet_proto.Services().ByName("WeatherStatsService").Methods()
+	return &weatherStatsServiceClient{
+		getConditionCounts: connect.NewClient[proto.ConditionCountsRequest, proto.ConditionCountsResponse](
+			httpClient,
+			baseURL+WeatherStatsServiceGetConditionCountsProcedure,
+			connect.WithSchema(weatherStatsServiceMethods.ByName("GetConditionCounts")),
+			connect.WithClientOptions(opts...),
+		),
+		getMunicipalityStats: connect.NewClient[proto.MunicipalityStatsRequest, proto.MunicipalityStatsResponse](
+			httpClient,
+			baseURL+WeatherStatsServiceGetMunicipalityStatsProcedure,
+			connect.WithSchema(weatherStatsServiceMethods.ByName("GetMunicipalityStats")),
+			connect.WithClientOptions(opts...),
+		),
+		getTimeSeries: connect.NewClient[proto.TimeSeriesRequest, proto.TimeSeriesResponse](
+			httpClient,
+			baseURL+WeatherStatsServiceGetTimeSeriesProcedure,
+			connect.WithSchema(weatherStatsServiceMethods.ByName("GetTimeSeries")),
+			connect.WithClientOptions(opts...),
+		),
+		watchStats: connect.NewClient[proto.WatchStatsRequest, proto.StatsUpdate](
+			httpClient,
+			baseURL+WeatherStatsServiceWatchStatsProcedure,
+			connect.WithSchema(weatherStatsServiceMethods.ByName("WatchStats")),
+			connect.WithClientOptions(opts...),
+		),
+		listRecentTweets: connect.NewClient[proto.ListRecentTweetsRequest, proto.ListRecentTweetsResponse](
+			httpClient,
+			baseURL+WeatherStatsServiceListRecentTweetsProcedure,
+			connect.WithSchema(weatherStatsServiceMethods.ByName("ListRecentTweets")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// weatherStatsServiceClient implements WeatherStatsServiceClient.
+type weatherStatsServiceClient struct {
+	getConditionCounts   *connect.Client[proto.ConditionCountsRequest, proto.ConditionCountsResponse]
+	getMunicipalityStats *connect.Client[proto.MunicipalityStatsRequest, proto.MunicipalityStatsResponse]
+	getTimeSeries        *connect.Client[proto.TimeSeriesRequest, proto.TimeSeriesResponse]
+	watchStats           *connect.Client[proto.WatchStatsRequest, proto.StatsUpdate]
+	listRecentTweets     *connect.Client[proto.ListRecentTweetsRequest, proto.ListRecentTweetsResponse]
+}
+
+// GetConditionCounts calls wethertweet.WeatherStatsService.GetConditionCounts.
+func (c *weatherStatsServiceClient) GetConditionCounts(ctx context.Context, req *connect.Request[proto.ConditionCountsRequest]) (*connect.Response[proto.ConditionCountsResponse], error) {
+	return c.getConditionCounts.CallUnary(ctx, req)
+}
+
+// GetMunicipalityStats calls wethertweet.WeatherStatsService.GetMunicipalityStats.
+func (c *weatherStatsServiceClient) GetMunicipalityStats(ctx context.Context, req *connect.Request[proto.MunicipalityStatsRequest]) (*connect.Response[proto.MunicipalityStatsResponse], error) {
+	return c.getMunicipalityStats.CallUnary(ctx, req)
+}
+
+// GetTimeSeries calls wethertweet.WeatherStatsService.GetTimeSeries.
+func (c *weatherStatsServiceClient) GetTimeSeries(ctx context.Context, req *connect.Request[proto.TimeSeriesRequest]) (*connect.Response[proto.TimeSeriesResponse], error) {
+	return c.getTimeSeries.CallUnary(ctx, req)
+}
+
+// WatchStats calls wethertweet.WeatherStatsService.WatchStats.
+func (c *weatherStatsServiceClient) WatchStats(ctx context.Context, req *connect.Request[proto.WatchStatsRequest]) (*connect.ServerStreamForClient[proto.StatsUpdate], error) {
+	return c.watchStats.CallServerStream(ctx, req)
+}
+
+// ListRecentTweets calls wethertweet.WeatherStatsService.ListRecentTweets.
+func (c *weatherStatsServiceClient) ListRecentTweets(ctx context.Context, req *connect.Request[proto.ListRecentTweetsRequest]) (*connect.Response[proto.ListRecentTweetsResponse], error) {
+	return c.listRecentTweets.CallUnary(ctx, req)
+}
+
+// WeatherStatsServiceHandler is an implementation of the wethertweet.WeatherStatsService service.
+type WeatherStatsServiceHandler interface {
+	GetConditionCounts(context.Context, *connect.Request[proto.ConditionCountsRequest]) (*connect.Response[proto.ConditionCountsResponse], error)
+	GetMunicipalityStats(context.Context, *connect.Request[proto.MunicipalityStatsRequest]) (*connect.Response[proto.MunicipalityStatsResponse], error)
+	GetTimeSeries(context.Context, *connect.Request[proto.TimeSeriesRequest]) (*connect.Response[proto.TimeSeriesResponse], error)
+	WatchStats(context.Context, *connect.Request[proto.WatchStatsRequest], *connect.ServerStream[proto.StatsUpdate]) error
+	ListRecentTweets(context.Context, *connect.Request[proto.ListRecentTweetsRequest]) (*connect.Response[proto.ListRecentTweetsResponse], error)
+}
+
+// NewWeatherStatsServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewWeatherStatsServiceHandler(svc WeatherStatsServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	weatherStatsServiceMethods := proto.File_proto_weather_tweet_proto.Services().ByName("WeatherStatsService").Methods()
+	weatherStatsServiceGetConditionCountsHandler := connect.NewUnaryHandler(
+		WeatherStatsServiceGetConditionCountsProcedure,
+		svc.GetConditionCounts,
+		connect.WithSchema(weatherStatsServiceMethods.ByName("GetConditionCounts")),
+		connect.WithHandlerOptions(opts...),
+	)
+	weatherStatsServiceGetMunicipalityStatsHandler := connect.NewUnaryHandler(
+		WeatherStatsServiceGetMunicipalityStatsProcedure,
+		svc.GetMunicipalityStats,
+		connect.WithSchema(weatherStatsServiceMethods.ByName("GetMunicipalityStats")),
+		connect.WithHandlerOptions(opts...),
+	)
+	weatherStatsServiceGetTimeSeriesHandler := connect.NewUnaryHandler(
+		WeatherStatsServiceGetTimeSeriesProcedure,
+		svc.GetTimeSeries,
+		connect.WithSchema(weatherStatsServiceMethods.ByName("GetTimeSeries")),
+		connect.WithHandlerOptions(opts...),
+	)
+	weatherStatsServiceWatchStatsHandler := connect.NewServerStreamHandler(
+		WeatherStatsServiceWatchStatsProcedure,
+		svc.WatchStats,
+		connect.WithSchema(weatherStatsServiceMethods.ByName("WatchStats")),
+		connect.WithHandlerOptions(opts...),
+	)
+	weatherStatsServiceListRecentTweetsHandler := connect.NewUnaryHandler(
+		WeatherStatsServiceListRecentTweetsProcedure,
+		svc.ListRecentTweets,
+		connect.WithSchema(weatherStatsServiceMethods.ByName("ListRecentTweets")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/wethertweet.WeatherStatsService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case WeatherStatsServiceGetConditionCountsProcedure:
+			weatherStatsServiceGetConditionCountsHandler.ServeHTTP(w, r)
+		case WeatherStatsServiceGetMunicipalityStatsProcedure:
+			weatherStatsServiceGetMunicipalityStatsHandler.ServeHTTP(w, r)
+		case WeatherStatsServiceGetTimeSeriesProcedure:
+			weatherStatsServiceGetTimeSeriesHandler.ServeHTTP(w, r)
+		case WeatherStatsServiceWatchStatsProcedure:
+			weatherStatsServiceWatchStatsHandler.ServeHTTP(w, r)
+		case WeatherStatsServiceListRecentTweetsProcedure:
+			weatherStatsServiceListRecentTweetsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedWeatherStatsServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedWeatherStatsServiceHandler struct{}
+
+func (UnimplementedWeatherStatsServiceHandler) GetConditionCounts(context.Context, *connect.Request[proto.ConditionCountsRequest]) (*connect.Response[proto.ConditionCountsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("wethertweet.WeatherStatsService.GetConditionCounts is not implemented"))
+}
+
+func (UnimplementedWeatherStatsServiceHandler) GetMunicipalityStats(context.Context, *connect.Request[proto.MunicipalityStatsRequest]) (*connect.Response[proto.MunicipalityStatsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("wethertweet.WeatherStatsService.GetMunicipalityStats is not implemented"))
+}
+
+func (UnimplementedWeatherStatsServiceHandler) GetTimeSeries(context.Context, *connect.Request[proto.TimeSeriesRequest]) (*connect.Response[proto.TimeSeriesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("wethertweet.WeatherStatsService.GetTimeSeries is not implemented"))
+}
+
+func (UnimplementedWeatherStatsServiceHandler) WatchStats(context.Context, *connect.Request[proto.WatchStatsRequest], *connect.ServerStream[proto.StatsUpdate]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("wethertweet.WeatherStatsService.WatchStats is not implemented"))
+}
+
+func (UnimplementedWeatherStatsServiceHandler) ListRecentTweets(context.Context, *connect.Request[proto.ListRecentTweetsRequest]) (*connect.Response[proto.ListRecentTweetsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("wethertweet.WeatherStatsService.ListRecentTweets is not implemented"))
+}
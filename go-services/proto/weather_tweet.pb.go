@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
-// 	protoc        v3.14.0
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
 // source: proto/weather_tweet.proto
 
 package proto
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -132,25 +133,77 @@ func (Weathers) EnumDescriptor() ([]byte, []int) {
 	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{1}
 }
 
+// Vista de los datos en Valkey que se consulta
+type Sources int32
+
+const (
+	// Cada tweet contado una sola vez, sin importar el broker
+	Sources_sources_combined Sources = 0
+	Sources_kafka            Sources = 1
+	Sources_rabbitmq         Sources = 2
+)
+
+// Enum value maps for Sources.
+var (
+	Sources_name = map[int32]string{
+		0: "sources_combined",
+		1: "kafka",
+		2: "rabbitmq",
+	}
+	Sources_value = map[string]int32{
+		"sources_combined": 0,
+		"kafka":            1,
+		"rabbitmq":         2,
+	}
+)
+
+func (x Sources) Enum() *Sources {
+	p := new(Sources)
+	*p = x
+	return p
+}
+
+func (x Sources) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Sources) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_weather_tweet_proto_enumTypes[2].Descriptor()
+}
+
+func (Sources) Type() protoreflect.EnumType {
+	return &file_proto_weather_tweet_proto_enumTypes[2]
+}
+
+func (x Sources) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Sources.Descriptor instead.
+func (Sources) EnumDescriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{2}
+}
+
 // Mensaje que se enviará
 type WeatherTweetRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Municipality Municipalities         `protobuf:"varint,1,opt,name=municipality,proto3,enum=wethertweet.Municipalities" json:"municipality,omitempty"`
+	Temperature  int32                  `protobuf:"varint,2,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Humidity     int32                  `protobuf:"varint,3,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Weather      Weathers               `protobuf:"varint,4,opt,name=weather,proto3,enum=wethertweet.Weathers" json:"weather,omitempty"`
+	// Identificador único asignado por el servidor gRPC si viene vacío
+	Id string `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	// Momento en que el servidor gRPC recibió el tweet (Unix, milisegundos)
+	ReceivedAt    int64 `protobuf:"varint,6,opt,name=received_at,json=receivedAt,proto3" json:"received_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Municipality Municipalities `protobuf:"varint,1,opt,name=municipality,proto3,enum=wethertweet.Municipalities" json:"municipality,omitempty"`
-	Temperature  int32          `protobuf:"varint,2,opt,name=temperature,proto3" json:"temperature,omitempty"`
-	Humidity     int32          `protobuf:"varint,3,opt,name=humidity,proto3" json:"humidity,omitempty"`
-	Weather      Weathers       `protobuf:"varint,4,opt,name=weather,proto3,enum=wethertweet.Weathers" json:"weather,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *WeatherTweetRequest) Reset() {
 	*x = WeatherTweetRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_weather_tweet_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_weather_tweet_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *WeatherTweetRequest) String() string {
@@ -161,7 +214,7 @@ func (*WeatherTweetRequest) ProtoMessage() {}
 
 func (x *WeatherTweetRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_weather_tweet_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -204,22 +257,33 @@ func (x *WeatherTweetRequest) GetWeather() Weathers {
 	return Weathers_weathers_unknown
 }
 
+func (x *WeatherTweetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WeatherTweetRequest) GetReceivedAt() int64 {
+	if x != nil {
+		return x.ReceivedAt
+	}
+	return 0
+}
+
 // Respuesta del servidor
 type WeatherTweetResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *WeatherTweetResponse) Reset() {
 	*x = WeatherTweetResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_weather_tweet_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_weather_tweet_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *WeatherTweetResponse) String() string {
@@ -230,7 +294,7 @@ func (*WeatherTweetResponse) ProtoMessage() {}
 
 func (x *WeatherTweetResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_weather_tweet_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -252,121 +316,1087 @@ func (x *WeatherTweetResponse) GetStatus() string {
 	return ""
 }
 
-var File_proto_weather_tweet_proto protoreflect.FileDescriptor
+// Cantidad de tweets de un clima
+type ConditionCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Weather       Weathers               `protobuf:"varint,1,opt,name=weather,proto3,enum=wethertweet.Weathers" json:"weather,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-var file_proto_weather_tweet_proto_rawDesc = []byte{
-	0x0a, 0x19, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x5f,
-	0x74, 0x77, 0x65, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x77, 0x65, 0x74,
-	0x68, 0x65, 0x72, 0x74, 0x77, 0x65, 0x65, 0x74, 0x22, 0xc5, 0x01, 0x0a, 0x13, 0x57, 0x65, 0x61,
-	0x74, 0x68, 0x65, 0x72, 0x54, 0x77, 0x65, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x3f, 0x0a, 0x0c, 0x6d, 0x75, 0x6e, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x69, 0x74, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x77, 0x65, 0x74, 0x68, 0x65, 0x72, 0x74,
-	0x77, 0x65, 0x65, 0x74, 0x2e, 0x4d, 0x75, 0x6e, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x69, 0x74,
-	0x69, 0x65, 0x73, 0x52, 0x0c, 0x6d, 0x75, 0x6e, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x69, 0x74,
-	0x79, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x12,
-	0x2f, 0x0a, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e,
-	0x32, 0x15, 0x2e, 0x77, 0x65, 0x74, 0x68, 0x65, 0x72, 0x74, 0x77, 0x65, 0x65, 0x74, 0x2e, 0x57,
-	0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x73, 0x52, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
-	0x22, 0x2e, 0x0a, 0x14, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x54, 0x77, 0x65, 0x65, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x2a, 0x64, 0x0a, 0x0e, 0x4d, 0x75, 0x6e, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x69, 0x74, 0x69,
-	0x65, 0x73, 0x12, 0x1a, 0x0a, 0x16, 0x6d, 0x75, 0x6e, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x69,
-	0x74, 0x69, 0x65, 0x73, 0x5f, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x09,
-	0x0a, 0x05, 0x6d, 0x69, 0x78, 0x63, 0x6f, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x67, 0x75, 0x61,
-	0x74, 0x65, 0x6d, 0x61, 0x6c, 0x61, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x61, 0x6d, 0x61, 0x74,
-	0x69, 0x74, 0x6c, 0x61, 0x6e, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x63, 0x68, 0x69, 0x6e, 0x61,
-	0x75, 0x74, 0x6c, 0x61, 0x10, 0x04, 0x2a, 0x4d, 0x0a, 0x08, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65,
-	0x72, 0x73, 0x12, 0x14, 0x0a, 0x10, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x73, 0x5f, 0x75,
-	0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x73, 0x75, 0x6e, 0x6e,
-	0x79, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x79, 0x10, 0x02, 0x12,
-	0x09, 0x0a, 0x05, 0x72, 0x61, 0x69, 0x6e, 0x79, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x66, 0x6f,
-	0x67, 0x67, 0x79, 0x10, 0x04, 0x32, 0x67, 0x0a, 0x13, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
-	0x54, 0x77, 0x65, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x50, 0x0a, 0x09,
-	0x53, 0x65, 0x6e, 0x64, 0x54, 0x77, 0x65, 0x65, 0x74, 0x12, 0x20, 0x2e, 0x77, 0x65, 0x74, 0x68,
-	0x65, 0x72, 0x74, 0x77, 0x65, 0x65, 0x74, 0x2e, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x54,
-	0x77, 0x65, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x77, 0x65,
-	0x74, 0x68, 0x65, 0x72, 0x74, 0x77, 0x65, 0x65, 0x74, 0x2e, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65,
-	0x72, 0x54, 0x77, 0x65, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x09,
-	0x5a, 0x07, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+func (x *ConditionCount) Reset() {
+	*x = ConditionCount{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-var (
-	file_proto_weather_tweet_proto_rawDescOnce sync.Once
-	file_proto_weather_tweet_proto_rawDescData = file_proto_weather_tweet_proto_rawDesc
-)
+func (x *ConditionCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func file_proto_weather_tweet_proto_rawDescGZIP() []byte {
-	file_proto_weather_tweet_proto_rawDescOnce.Do(func() {
-		file_proto_weather_tweet_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_weather_tweet_proto_rawDescData)
-	})
-	return file_proto_weather_tweet_proto_rawDescData
+func (*ConditionCount) ProtoMessage() {}
+
+func (x *ConditionCount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var file_proto_weather_tweet_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_weather_tweet_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_proto_weather_tweet_proto_goTypes = []interface{}{
-	(Municipalities)(0),          // 0: wethertweet.Municipalities
-	(Weathers)(0),                // 1: wethertweet.Weathers
-	(*WeatherTweetRequest)(nil),  // 2: wethertweet.WeatherTweetRequest
-	(*WeatherTweetResponse)(nil), // 3: wethertweet.WeatherTweetResponse
+// Deprecated: Use ConditionCount.ProtoReflect.Descriptor instead.
+func (*ConditionCount) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{2}
 }
-var file_proto_weather_tweet_proto_depIdxs = []int32{
-	0, // 0: wethertweet.WeatherTweetRequest.municipality:type_name -> wethertweet.Municipalities
-	1, // 1: wethertweet.WeatherTweetRequest.weather:type_name -> wethertweet.Weathers
-	2, // 2: wethertweet.WeatherTweetService.SendTweet:input_type -> wethertweet.WeatherTweetRequest
-	3, // 3: wethertweet.WeatherTweetService.SendTweet:output_type -> wethertweet.WeatherTweetResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+
+func (x *ConditionCount) GetWeather() Weathers {
+	if x != nil {
+		return x.Weather
+	}
+	return Weathers_weathers_unknown
 }
 
-func init() { file_proto_weather_tweet_proto_init() }
-func file_proto_weather_tweet_proto_init() {
-	if File_proto_weather_tweet_proto != nil {
-		return
+func (x *ConditionCount) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type ConditionCountsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        Sources                `protobuf:"varint,1,opt,name=source,proto3,enum=wethertweet.Sources" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConditionCountsRequest) Reset() {
+	*x = ConditionCountsRequest{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConditionCountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConditionCountsRequest) ProtoMessage() {}
+
+func (x *ConditionCountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConditionCountsRequest.ProtoReflect.Descriptor instead.
+func (*ConditionCountsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ConditionCountsRequest) GetSource() Sources {
+	if x != nil {
+		return x.Source
+	}
+	return Sources_sources_combined
+}
+
+type ConditionCountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Counts        []*ConditionCount      `protobuf:"bytes,1,rep,name=counts,proto3" json:"counts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConditionCountsResponse) Reset() {
+	*x = ConditionCountsResponse{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConditionCountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConditionCountsResponse) ProtoMessage() {}
+
+func (x *ConditionCountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_proto_weather_tweet_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WeatherTweetRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConditionCountsResponse.ProtoReflect.Descriptor instead.
+func (*ConditionCountsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConditionCountsResponse) GetCounts() []*ConditionCount {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+type MunicipalityStatsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Source Sources                `protobuf:"varint,1,opt,name=source,proto3,enum=wethertweet.Sources" json:"source,omitempty"`
+	// Municipios a consultar; vacío para todos
+	Municipalities []Municipalities `protobuf:"varint,2,rep,packed,name=municipalities,proto3,enum=wethertweet.Municipalities" json:"municipalities,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MunicipalityStatsRequest) Reset() {
+	*x = MunicipalityStatsRequest{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MunicipalityStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MunicipalityStatsRequest) ProtoMessage() {}
+
+func (x *MunicipalityStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MunicipalityStatsRequest.ProtoReflect.Descriptor instead.
+func (*MunicipalityStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MunicipalityStatsRequest) GetSource() Sources {
+	if x != nil {
+		return x.Source
+	}
+	return Sources_sources_combined
+}
+
+func (x *MunicipalityStatsRequest) GetMunicipalities() []Municipalities {
+	if x != nil {
+		return x.Municipalities
+	}
+	return nil
+}
+
+// Estadísticas acumuladas de un municipio
+type MunicipalityStats struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Municipality       Municipalities         `protobuf:"varint,1,opt,name=municipality,proto3,enum=wethertweet.Municipalities" json:"municipality,omitempty"`
+	Count              int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	AverageTemperature float64                `protobuf:"fixed64,3,opt,name=average_temperature,json=averageTemperature,proto3" json:"average_temperature,omitempty"`
+	AverageHumidity    float64                `protobuf:"fixed64,4,opt,name=average_humidity,json=averageHumidity,proto3" json:"average_humidity,omitempty"`
+	Conditions         []*ConditionCount      `protobuf:"bytes,5,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MunicipalityStats) Reset() {
+	*x = MunicipalityStats{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MunicipalityStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MunicipalityStats) ProtoMessage() {}
+
+func (x *MunicipalityStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MunicipalityStats.ProtoReflect.Descriptor instead.
+func (*MunicipalityStats) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MunicipalityStats) GetMunicipality() Municipalities {
+	if x != nil {
+		return x.Municipality
+	}
+	return Municipalities_municipalities_unknown
+}
+
+func (x *MunicipalityStats) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *MunicipalityStats) GetAverageTemperature() float64 {
+	if x != nil {
+		return x.AverageTemperature
+	}
+	return 0
+}
+
+func (x *MunicipalityStats) GetAverageHumidity() float64 {
+	if x != nil {
+		return x.AverageHumidity
+	}
+	return 0
+}
+
+func (x *MunicipalityStats) GetConditions() []*ConditionCount {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+type MunicipalityStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stats         []*MunicipalityStats   `protobuf:"bytes,1,rep,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MunicipalityStatsResponse) Reset() {
+	*x = MunicipalityStatsResponse{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MunicipalityStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MunicipalityStatsResponse) ProtoMessage() {}
+
+func (x *MunicipalityStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_proto_weather_tweet_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WeatherTweetResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MunicipalityStatsResponse.ProtoReflect.Descriptor instead.
+func (*MunicipalityStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MunicipalityStatsResponse) GetStats() []*MunicipalityStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type TimeSeriesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Source Sources                `protobuf:"varint,1,opt,name=source,proto3,enum=wethertweet.Sources" json:"source,omitempty"`
+	// Sin municipio se suman todos los municipios
+	Municipality *Municipalities `protobuf:"varint,2,opt,name=municipality,proto3,enum=wethertweet.Municipalities,oneof" json:"municipality,omitempty"`
+	// Sin clima se cuentan todos los tweets
+	Weather *Weathers `protobuf:"varint,3,opt,name=weather,proto3,enum=wethertweet.Weathers,oneof" json:"weather,omitempty"`
+	// Rango de tiempo (Unix, milisegundos); por defecto la última hora
+	From int64 `protobuf:"varint,4,opt,name=from,proto3" json:"from,omitempty"`
+	To   int64 `protobuf:"varint,5,opt,name=to,proto3" json:"to,omitempty"`
+	// Ancho de cada punto en segundos, múltiplo de 60; por defecto 60
+	ResolutionSeconds int64 `protobuf:"varint,6,opt,name=resolution_seconds,json=resolutionSeconds,proto3" json:"resolution_seconds,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TimeSeriesRequest) Reset() {
+	*x = TimeSeriesRequest{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeSeriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeriesRequest) ProtoMessage() {}
+
+func (x *TimeSeriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSeriesRequest.ProtoReflect.Descriptor instead.
+func (*TimeSeriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TimeSeriesRequest) GetSource() Sources {
+	if x != nil {
+		return x.Source
+	}
+	return Sources_sources_combined
+}
+
+func (x *TimeSeriesRequest) GetMunicipality() Municipalities {
+	if x != nil && x.Municipality != nil {
+		return *x.Municipality
+	}
+	return Municipalities_municipalities_unknown
+}
+
+func (x *TimeSeriesRequest) GetWeather() Weathers {
+	if x != nil && x.Weather != nil {
+		return *x.Weather
+	}
+	return Weathers_weathers_unknown
+}
+
+func (x *TimeSeriesRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *TimeSeriesRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *TimeSeriesRequest) GetResolutionSeconds() int64 {
+	if x != nil {
+		return x.ResolutionSeconds
+	}
+	return 0
+}
+
+// Tweets recibidos en un intervalo; los promedios incluyen todos los climas
+type TimeSeriesPoint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Inicio del intervalo (Unix, milisegundos)
+	Time               int64   `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Count              int64   `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	AverageTemperature float64 `protobuf:"fixed64,3,opt,name=average_temperature,json=averageTemperature,proto3" json:"average_temperature,omitempty"`
+	AverageHumidity    float64 `protobuf:"fixed64,4,opt,name=average_humidity,json=averageHumidity,proto3" json:"average_humidity,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TimeSeriesPoint) Reset() {
+	*x = TimeSeriesPoint{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeSeriesPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeriesPoint) ProtoMessage() {}
+
+func (x *TimeSeriesPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSeriesPoint.ProtoReflect.Descriptor instead.
+func (*TimeSeriesPoint) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TimeSeriesPoint) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *TimeSeriesPoint) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *TimeSeriesPoint) GetAverageTemperature() float64 {
+	if x != nil {
+		return x.AverageTemperature
+	}
+	return 0
+}
+
+func (x *TimeSeriesPoint) GetAverageHumidity() float64 {
+	if x != nil {
+		return x.AverageHumidity
+	}
+	return 0
+}
+
+type TimeSeriesResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Points            []*TimeSeriesPoint     `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	ResolutionSeconds int64                  `protobuf:"varint,2,opt,name=resolution_seconds,json=resolutionSeconds,proto3" json:"resolution_seconds,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TimeSeriesResponse) Reset() {
+	*x = TimeSeriesResponse{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeSeriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeriesResponse) ProtoMessage() {}
+
+func (x *TimeSeriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSeriesResponse.ProtoReflect.Descriptor instead.
+func (*TimeSeriesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TimeSeriesResponse) GetPoints() []*TimeSeriesPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *TimeSeriesResponse) GetResolutionSeconds() int64 {
+	if x != nil {
+		return x.ResolutionSeconds
+	}
+	return 0
+}
+
+type WatchStatsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Source Sources                `protobuf:"varint,1,opt,name=source,proto3,enum=wethertweet.Sources" json:"source,omitempty"`
+	// Municipios a observar; vacío para todos
+	Municipalities []Municipalities `protobuf:"varint,2,rep,packed,name=municipalities,proto3,enum=wethertweet.Municipalities" json:"municipalities,omitempty"`
+	// Climas a observar; vacío para todos
+	Weathers []Weathers `protobuf:"varint,3,rep,packed,name=weathers,proto3,enum=wethertweet.Weathers" json:"weathers,omitempty"`
+	// Tiempo mínimo entre actualizaciones en milisegundos; por defecto 1000
+	IntervalMs    int64 `protobuf:"varint,4,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStatsRequest) Reset() {
+	*x = WatchStatsRequest{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStatsRequest) ProtoMessage() {}
+
+func (x *WatchStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStatsRequest.ProtoReflect.Descriptor instead.
+func (*WatchStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchStatsRequest) GetSource() Sources {
+	if x != nil {
+		return x.Source
+	}
+	return Sources_sources_combined
+}
+
+func (x *WatchStatsRequest) GetMunicipalities() []Municipalities {
+	if x != nil {
+		return x.Municipalities
+	}
+	return nil
+}
+
+func (x *WatchStatsRequest) GetWeathers() []Weathers {
+	if x != nil {
+		return x.Weathers
+	}
+	return nil
+}
+
+func (x *WatchStatsRequest) GetIntervalMs() int64 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+// Estadísticas de los municipios que cambiaron desde la última
+// actualización; la primera incluye todos los municipios observados
+type StatsUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Stats []*MunicipalityStats   `protobuf:"bytes,1,rep,name=stats,proto3" json:"stats,omitempty"`
+	// Momento de la lectura (Unix, milisegundos)
+	Time          int64 `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsUpdate) Reset() {
+	*x = StatsUpdate{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsUpdate) ProtoMessage() {}
+
+func (x *StatsUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsUpdate.ProtoReflect.Descriptor instead.
+func (*StatsUpdate) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StatsUpdate) GetStats() []*MunicipalityStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *StatsUpdate) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type ListRecentTweetsRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Municipality Municipalities         `protobuf:"varint,1,opt,name=municipality,proto3,enum=wethertweet.Municipalities" json:"municipality,omitempty"`
+	// Broker que almacenó los tweets; sources_combined para ambos
+	Source Sources `protobuf:"varint,2,opt,name=source,proto3,enum=wethertweet.Sources" json:"source,omitempty"`
+	// Tweets por página; por defecto 50, máximo 500
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token de la respuesta anterior; vacío para los más recientes
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecentTweetsRequest) Reset() {
+	*x = ListRecentTweetsRequest{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecentTweetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecentTweetsRequest) ProtoMessage() {}
+
+func (x *ListRecentTweetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecentTweetsRequest.ProtoReflect.Descriptor instead.
+func (*ListRecentTweetsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListRecentTweetsRequest) GetMunicipality() Municipalities {
+	if x != nil {
+		return x.Municipality
+	}
+	return Municipalities_municipalities_unknown
+}
+
+func (x *ListRecentTweetsRequest) GetSource() Sources {
+	if x != nil {
+		return x.Source
+	}
+	return Sources_sources_combined
+}
+
+func (x *ListRecentTweetsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRecentTweetsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Tweet tal como lo almacenó un broker
+type RecentTweet struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source       Sources                `protobuf:"varint,2,opt,name=source,proto3,enum=wethertweet.Sources" json:"source,omitempty"`
+	Municipality Municipalities         `protobuf:"varint,3,opt,name=municipality,proto3,enum=wethertweet.Municipalities" json:"municipality,omitempty"`
+	Weather      Weathers               `protobuf:"varint,4,opt,name=weather,proto3,enum=wethertweet.Weathers" json:"weather,omitempty"`
+	Temperature  int32                  `protobuf:"varint,5,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Humidity     int32                  `protobuf:"varint,6,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	// Momento en que el servidor gRPC recibió el tweet (Unix, milisegundos)
+	ReceivedAt int64 `protobuf:"varint,7,opt,name=received_at,json=receivedAt,proto3" json:"received_at,omitempty"`
+	// Momento en que el consumidor almacenó el tweet (Unix, milisegundos)
+	StoredAt      int64 `protobuf:"varint,8,opt,name=stored_at,json=storedAt,proto3" json:"stored_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecentTweet) Reset() {
+	*x = RecentTweet{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecentTweet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecentTweet) ProtoMessage() {}
+
+func (x *RecentTweet) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecentTweet.ProtoReflect.Descriptor instead.
+func (*RecentTweet) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RecentTweet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RecentTweet) GetSource() Sources {
+	if x != nil {
+		return x.Source
+	}
+	return Sources_sources_combined
+}
+
+func (x *RecentTweet) GetMunicipality() Municipalities {
+	if x != nil {
+		return x.Municipality
+	}
+	return Municipalities_municipalities_unknown
+}
+
+func (x *RecentTweet) GetWeather() Weathers {
+	if x != nil {
+		return x.Weather
+	}
+	return Weathers_weathers_unknown
+}
+
+func (x *RecentTweet) GetTemperature() int32 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *RecentTweet) GetHumidity() int32 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *RecentTweet) GetReceivedAt() int64 {
+	if x != nil {
+		return x.ReceivedAt
+	}
+	return 0
+}
+
+func (x *RecentTweet) GetStoredAt() int64 {
+	if x != nil {
+		return x.StoredAt
+	}
+	return 0
+}
+
+type ListRecentTweetsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Del más reciente al más antiguo
+	Tweets []*RecentTweet `protobuf:"bytes,1,rep,name=tweets,proto3" json:"tweets,omitempty"`
+	// Vacío cuando no hay más tweets
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecentTweetsResponse) Reset() {
+	*x = ListRecentTweetsResponse{}
+	mi := &file_proto_weather_tweet_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecentTweetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecentTweetsResponse) ProtoMessage() {}
+
+func (x *ListRecentTweetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_weather_tweet_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecentTweetsResponse.ProtoReflect.Descriptor instead.
+func (*ListRecentTweetsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_weather_tweet_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListRecentTweetsResponse) GetTweets() []*RecentTweet {
+	if x != nil {
+		return x.Tweets
+	}
+	return nil
+}
+
+func (x *ListRecentTweetsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+var File_proto_weather_tweet_proto protoreflect.FileDescriptor
+
+const file_proto_weather_tweet_proto_rawDesc = "" +
+	"\n" +
+	"\x19proto/weather_tweet.proto\x12\vwethertweet\"\xf6\x01\n" +
+	"\x13WeatherTweetRequest\x12?\n" +
+	"\fmunicipality\x18\x01 \x01(\x0e2\x1b.wethertweet.MunicipalitiesR\fmunicipality\x12 \n" +
+	"\vtemperature\x18\x02 \x01(\x05R\vtemperature\x12\x1a\n" +
+	"\bhumidity\x18\x03 \x01(\x05R\bhumidity\x12/\n" +
+	"\aweather\x18\x04 \x01(\x0e2\x15.wethertweet.WeathersR\aweather\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\tR\x02id\x12\x1f\n" +
+	"\vreceived_at\x18\x06 \x01(\x03R\n" +
+	"receivedAt\".\n" +
+	"\x14WeatherTweetResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"W\n" +
+	"\x0eConditionCount\x12/\n" +
+	"\aweather\x18\x01 \x01(\x0e2\x15.wethertweet.WeathersR\aweather\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"F\n" +
+	"\x16ConditionCountsRequest\x12,\n" +
+	"\x06source\x18\x01 \x01(\x0e2\x14.wethertweet.SourcesR\x06source\"N\n" +
+	"\x17ConditionCountsResponse\x123\n" +
+	"\x06counts\x18\x01 \x03(\v2\x1b.wethertweet.ConditionCountR\x06counts\"\x8d\x01\n" +
+	"\x18MunicipalityStatsRequest\x12,\n" +
+	"\x06source\x18\x01 \x01(\x0e2\x14.wethertweet.SourcesR\x06source\x12C\n" +
+	"\x0emunicipalities\x18\x02 \x03(\x0e2\x1b.wethertweet.MunicipalitiesR\x0emunicipalities\"\x83\x02\n" +
+	"\x11MunicipalityStats\x12?\n" +
+	"\fmunicipality\x18\x01 \x01(\x0e2\x1b.wethertweet.MunicipalitiesR\fmunicipality\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12/\n" +
+	"\x13average_temperature\x18\x03 \x01(\x01R\x12averageTemperature\x12)\n" +
+	"\x10average_humidity\x18\x04 \x01(\x01R\x0faverageHumidity\x12;\n" +
+	"\n" +
+	"conditions\x18\x05 \x03(\v2\x1b.wethertweet.ConditionCountR\n" +
+	"conditions\"Q\n" +
+	"\x19MunicipalityStatsResponse\x124\n" +
+	"\x05stats\x18\x01 \x03(\v2\x1e.wethertweet.MunicipalityStatsR\x05stats\"\xad\x02\n" +
+	"\x11TimeSeriesRequest\x12,\n" +
+	"\x06source\x18\x01 \x01(\x0e2\x14.wethertweet.SourcesR\x06source\x12D\n" +
+	"\fmunicipality\x18\x02 \x01(\x0e2\x1b.wethertweet.MunicipalitiesH\x00R\fmunicipality\x88\x01\x01\x124\n" +
+	"\aweather\x18\x03 \x01(\x0e2\x15.wethertweet.WeathersH\x01R\aweather\x88\x01\x01\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\x03R\x02to\x12-\n" +
+	"\x12resolution_seconds\x18\x06 \x01(\x03R\x11resolutionSecondsB\x0f\n" +
+	"\r_municipalityB\n" +
+	"\n" +
+	"\b_weather\"\x97\x01\n" +
+	"\x0fTimeSeriesPoint\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12/\n" +
+	"\x13average_temperature\x18\x03 \x01(\x01R\x12averageTemperature\x12)\n" +
+	"\x10average_humidity\x18\x04 \x01(\x01R\x0faverageHumidity\"y\n" +
+	"\x12TimeSeriesResponse\x124\n" +
+	"\x06points\x18\x01 \x03(\v2\x1c.wethertweet.TimeSeriesPointR\x06points\x12-\n" +
+	"\x12resolution_seconds\x18\x02 \x01(\x03R\x11resolutionSeconds\"\xda\x01\n" +
+	"\x11WatchStatsRequest\x12,\n" +
+	"\x06source\x18\x01 \x01(\x0e2\x14.wethertweet.SourcesR\x06source\x12C\n" +
+	"\x0emunicipalities\x18\x02 \x03(\x0e2\x1b.wethertweet.MunicipalitiesR\x0emunicipalities\x121\n" +
+	"\bweathers\x18\x03 \x03(\x0e2\x15.wethertweet.WeathersR\bweathers\x12\x1f\n" +
+	"\vinterval_ms\x18\x04 \x01(\x03R\n" +
+	"intervalMs\"W\n" +
+	"\vStatsUpdate\x124\n" +
+	"\x05stats\x18\x01 \x03(\v2\x1e.wethertweet.MunicipalityStatsR\x05stats\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x03R\x04time\"\xc4\x01\n" +
+	"\x17ListRecentTweetsRequest\x12?\n" +
+	"\fmunicipality\x18\x01 \x01(\x0e2\x1b.wethertweet.MunicipalitiesR\fmunicipality\x12,\n" +
+	"\x06source\x18\x02 \x01(\x0e2\x14.wethertweet.SourcesR\x06source\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"\xb9\x02\n" +
+	"\vRecentTweet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\x06source\x18\x02 \x01(\x0e2\x14.wethertweet.SourcesR\x06source\x12?\n" +
+	"\fmunicipality\x18\x03 \x01(\x0e2\x1b.wethertweet.MunicipalitiesR\fmunicipality\x12/\n" +
+	"\aweather\x18\x04 \x01(\x0e2\x15.wethertweet.WeathersR\aweather\x12 \n" +
+	"\vtemperature\x18\x05 \x01(\x05R\vtemperature\x12\x1a\n" +
+	"\bhumidity\x18\x06 \x01(\x05R\bhumidity\x12\x1f\n" +
+	"\vreceived_at\x18\a \x01(\x03R\n" +
+	"receivedAt\x12\x1b\n" +
+	"\tstored_at\x18\b \x01(\x03R\bstoredAt\"t\n" +
+	"\x18ListRecentTweetsResponse\x120\n" +
+	"\x06tweets\x18\x01 \x03(\v2\x18.wethertweet.RecentTweetR\x06tweets\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken*d\n" +
+	"\x0eMunicipalities\x12\x1a\n" +
+	"\x16municipalities_unknown\x10\x00\x12\t\n" +
+	"\x05mixco\x10\x01\x12\r\n" +
+	"\tguatemala\x10\x02\x12\r\n" +
+	"\tamatitlan\x10\x03\x12\r\n" +
+	"\tchinautla\x10\x04*M\n" +
+	"\bWeathers\x12\x14\n" +
+	"\x10weathers_unknown\x10\x00\x12\t\n" +
+	"\x05sunny\x10\x01\x12\n" +
+	"\n" +
+	"\x06cloudy\x10\x02\x12\t\n" +
+	"\x05rainy\x10\x03\x12\t\n" +
+	"\x05foggy\x10\x04*8\n" +
+	"\aSources\x12\x14\n" +
+	"\x10sources_combined\x10\x00\x12\t\n" +
+	"\x05kafka\x10\x01\x12\f\n" +
+	"\brabbitmq\x10\x022g\n" +
+	"\x13WeatherTweetService\x12P\n" +
+	"\tSendTweet\x12 .wethertweet.WeatherTweetRequest\x1a!.wethertweet.WeatherTweetResponse2\xda\x03\n" +
+	"\x13WeatherStatsService\x12_\n" +
+	"\x12GetConditionCounts\x12#.wethertweet.ConditionCountsRequest\x1a$.wethertweet.ConditionCountsResponse\x12e\n" +
+	"\x14GetMunicipalityStats\x12%.wethertweet.MunicipalityStatsRequest\x1a&.wethertweet.MunicipalityStatsResponse\x12P\n" +
+	"\rGetTimeSeries\x12\x1e.wethertweet.TimeSeriesRequest\x1a\x1f.wethertweet.TimeSeriesResponse\x12H\n" +
+	"\n" +
+	"WatchStats\x12\x1e.wethertweet.WatchStatsRequest\x1a\x18.wethertweet.StatsUpdate0\x01\x12_\n" +
+	"\x10ListRecentTweets\x12$.wethertweet.ListRecentTweetsRequest\x1a%.wethertweet.ListRecentTweetsResponseB\tZ\a./protob\x06proto3"
+
+var (
+	file_proto_weather_tweet_proto_rawDescOnce sync.Once
+	file_proto_weather_tweet_proto_rawDescData []byte
+)
+
+func file_proto_weather_tweet_proto_rawDescGZIP() []byte {
+	file_proto_weather_tweet_proto_rawDescOnce.Do(func() {
+		file_proto_weather_tweet_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_weather_tweet_proto_rawDesc), len(file_proto_weather_tweet_proto_rawDesc)))
+	})
+	return file_proto_weather_tweet_proto_rawDescData
+}
+
+var file_proto_weather_tweet_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_weather_tweet_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_proto_weather_tweet_proto_goTypes = []any{
+	(Municipalities)(0),               // 0: wethertweet.Municipalities
+	(Weathers)(0),                     // 1: wethertweet.Weathers
+	(Sources)(0),                      // 2: wethertweet.Sources
+	(*WeatherTweetRequest)(nil),       // 3: wethertweet.WeatherTweetRequest
+	(*WeatherTweetResponse)(nil),      // 4: wethertweet.WeatherTweetResponse
+	(*ConditionCount)(nil),            // 5: wethertweet.ConditionCount
+	(*ConditionCountsRequest)(nil),    // 6: wethertweet.ConditionCountsRequest
+	(*ConditionCountsResponse)(nil),   // 7: wethertweet.ConditionCountsResponse
+	(*MunicipalityStatsRequest)(nil),  // 8: wethertweet.MunicipalityStatsRequest
+	(*MunicipalityStats)(nil),         // 9: wethertweet.MunicipalityStats
+	(*MunicipalityStatsResponse)(nil), // 10: wethertweet.MunicipalityStatsResponse
+	(*TimeSeriesRequest)(nil),         // 11: wethertweet.TimeSeriesRequest
+	(*TimeSeriesPoint)(nil),           // 12: wethertweet.TimeSeriesPoint
+	(*TimeSeriesResponse)(nil),        // 13: wethertweet.TimeSeriesResponse
+	(*WatchStatsRequest)(nil),         // 14: wethertweet.WatchStatsRequest
+	(*StatsUpdate)(nil),               // 15: wethertweet.StatsUpdate
+	(*ListRecentTweetsRequest)(nil),   // 16: wethertweet.ListRecentTweetsRequest
+	(*RecentTweet)(nil),               // 17: wethertweet.RecentTweet
+	(*ListRecentTweetsResponse)(nil),  // 18: wethertweet.ListRecentTweetsResponse
+}
+var file_proto_weather_tweet_proto_depIdxs = []int32{
+	0,  // 0: wethertweet.WeatherTweetRequest.municipality:type_name -> wethertweet.Municipalities
+	1,  // 1: wethertweet.WeatherTweetRequest.weather:type_name -> wethertweet.Weathers
+	1,  // 2: wethertweet.ConditionCount.weather:type_name -> wethertweet.Weathers
+	2,  // 3: wethertweet.ConditionCountsRequest.source:type_name -> wethertweet.Sources
+	5,  // 4: wethertweet.ConditionCountsResponse.counts:type_name -> wethertweet.ConditionCount
+	2,  // 5: wethertweet.MunicipalityStatsRequest.source:type_name -> wethertweet.Sources
+	0,  // 6: wethertweet.MunicipalityStatsRequest.municipalities:type_name -> wethertweet.Municipalities
+	0,  // 7: wethertweet.MunicipalityStats.municipality:type_name -> wethertweet.Municipalities
+	5,  // 8: wethertweet.MunicipalityStats.conditions:type_name -> wethertweet.ConditionCount
+	9,  // 9: wethertweet.MunicipalityStatsResponse.stats:type_name -> wethertweet.MunicipalityStats
+	2,  // 10: wethertweet.TimeSeriesRequest.source:type_name -> wethertweet.Sources
+	0,  // 11: wethertweet.TimeSeriesRequest.municipality:type_name -> wethertweet.Municipalities
+	1,  // 12: wethertweet.TimeSeriesRequest.weather:type_name -> wethertweet.Weathers
+	12, // 13: wethertweet.TimeSeriesResponse.points:type_name -> wethertweet.TimeSeriesPoint
+	2,  // 14: wethertweet.WatchStatsRequest.source:type_name -> wethertweet.Sources
+	0,  // 15: wethertweet.WatchStatsRequest.municipalities:type_name -> wethertweet.Municipalities
+	1,  // 16: wethertweet.WatchStatsRequest.weathers:type_name -> wethertweet.Weathers
+	9,  // 17: wethertweet.StatsUpdate.stats:type_name -> wethertweet.MunicipalityStats
+	0,  // 18: wethertweet.ListRecentTweetsRequest.municipality:type_name -> wethertweet.Municipalities
+	2,  // 19: wethertweet.ListRecentTweetsRequest.source:type_name -> wethertweet.Sources
+	2,  // 20: wethertweet.RecentTweet.source:type_name -> wethertweet.Sources
+	0,  // 21: wethertweet.RecentTweet.municipality:type_name -> wethertweet.Municipalities
+	1,  // 22: wethertweet.RecentTweet.weather:type_name -> wethertweet.Weathers
+	17, // 23: wethertweet.ListRecentTweetsResponse.tweets:type_name -> wethertweet.RecentTweet
+	3,  // 24: wethertweet.WeatherTweetService.SendTweet:input_type -> wethertweet.WeatherTweetRequest
+	6,  // 25: wethertweet.WeatherStatsService.GetConditionCounts:input_type -> wethertweet.ConditionCountsRequest
+	8,  // 26: wethertweet.WeatherStatsService.GetMunicipalityStats:input_type -> wethertweet.MunicipalityStatsRequest
+	11, // 27: wethertweet.WeatherStatsService.GetTimeSeries:input_type -> wethertweet.TimeSeriesRequest
+	14, // 28: wethertweet.WeatherStatsService.WatchStats:input_type -> wethertweet.WatchStatsRequest
+	16, // 29: wethertweet.WeatherStatsService.ListRecentTweets:input_type -> wethertweet.ListRecentTweetsRequest
+	4,  // 30: wethertweet.WeatherTweetService.SendTweet:output_type -> wethertweet.WeatherTweetResponse
+	7,  // 31: wethertweet.WeatherStatsService.GetConditionCounts:output_type -> wethertweet.ConditionCountsResponse
+	10, // 32: wethertweet.WeatherStatsService.GetMunicipalityStats:output_type -> wethertweet.MunicipalityStatsResponse
+	13, // 33: wethertweet.WeatherStatsService.GetTimeSeries:output_type -> wethertweet.TimeSeriesResponse
+	15, // 34: wethertweet.WeatherStatsService.WatchStats:output_type -> wethertweet.StatsUpdate
+	18, // 35: wethertweet.WeatherStatsService.ListRecentTweets:output_type -> wethertweet.ListRecentTweetsResponse
+	30, // [30:36] is the sub-list for method output_type
+	24, // [24:30] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_proto_weather_tweet_proto_init() }
+func file_proto_weather_tweet_proto_init() {
+	if File_proto_weather_tweet_proto != nil {
+		return
 	}
+	file_proto_weather_tweet_proto_msgTypes[8].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_proto_weather_tweet_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   2,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_weather_tweet_proto_rawDesc), len(file_proto_weather_tweet_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   16,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_proto_weather_tweet_proto_goTypes,
 		DependencyIndexes: file_proto_weather_tweet_proto_depIdxs,
@@ -374,7 +1404,6 @@ func file_proto_weather_tweet_proto_init() {
 		MessageInfos:      file_proto_weather_tweet_proto_msgTypes,
 	}.Build()
 	File_proto_weather_tweet_proto = out.File
-	file_proto_weather_tweet_proto_rawDesc = nil
 	file_proto_weather_tweet_proto_goTypes = nil
 	file_proto_weather_tweet_proto_depIdxs = nil
 }
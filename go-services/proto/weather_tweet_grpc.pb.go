@@ -1,4 +1,8 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proto/weather_tweet.proto
 
 package proto
 
@@ -11,12 +15,18 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.32.0 or later.
-const _ = grpc.SupportPackageIsVersion7
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WeatherTweetService_SendTweet_FullMethodName = "/wethertweet.WeatherTweetService/SendTweet"
+)
 
 // WeatherTweetServiceClient is the client API for WeatherTweetService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Servicio gRPC
 type WeatherTweetServiceClient interface {
 	SendTweet(ctx context.Context, in *WeatherTweetRequest, opts ...grpc.CallOption) (*WeatherTweetResponse, error)
 }
@@ -30,8 +40,9 @@ func NewWeatherTweetServiceClient(cc grpc.ClientConnInterface) WeatherTweetServi
 }
 
 func (c *weatherTweetServiceClient) SendTweet(ctx context.Context, in *WeatherTweetRequest, opts ...grpc.CallOption) (*WeatherTweetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(WeatherTweetResponse)
-	err := c.cc.Invoke(ctx, "/wethertweet.WeatherTweetService/SendTweet", in, out, opts...)
+	err := c.cc.Invoke(ctx, WeatherTweetService_SendTweet_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -40,20 +51,26 @@ func (c *weatherTweetServiceClient) SendTweet(ctx context.Context, in *WeatherTw
 
 // WeatherTweetServiceServer is the server API for WeatherTweetService service.
 // All implementations must embed UnimplementedWeatherTweetServiceServer
-// for forward compatibility
+// for forward compatibility.
+//
+// Servicio gRPC
 type WeatherTweetServiceServer interface {
 	SendTweet(context.Context, *WeatherTweetRequest) (*WeatherTweetResponse, error)
 	mustEmbedUnimplementedWeatherTweetServiceServer()
 }
 
-// UnimplementedWeatherTweetServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedWeatherTweetServiceServer struct {
-}
+// UnimplementedWeatherTweetServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWeatherTweetServiceServer struct{}
 
 func (UnimplementedWeatherTweetServiceServer) SendTweet(context.Context, *WeatherTweetRequest) (*WeatherTweetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendTweet not implemented")
 }
 func (UnimplementedWeatherTweetServiceServer) mustEmbedUnimplementedWeatherTweetServiceServer() {}
+func (UnimplementedWeatherTweetServiceServer) testEmbeddedByValue()                             {}
 
 // UnsafeWeatherTweetServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to WeatherTweetServiceServer will
@@ -63,6 +80,13 @@ type UnsafeWeatherTweetServiceServer interface {
 }
 
 func RegisterWeatherTweetServiceServer(s grpc.ServiceRegistrar, srv WeatherTweetServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWeatherTweetServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&WeatherTweetService_ServiceDesc, srv)
 }
 
@@ -76,7 +100,7 @@ func _WeatherTweetService_SendTweet_Handler(srv interface{}, ctx context.Context
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/wethertweet.WeatherTweetService/SendTweet",
+		FullMethod: WeatherTweetService_SendTweet_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
 		return srv.(WeatherTweetServiceServer).SendTweet(ctx, req.(*WeatherTweetRequest))
@@ -99,3 +123,265 @@ var WeatherTweetService_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/weather_tweet.proto",
 }
+
+const (
+	WeatherStatsService_GetConditionCounts_FullMethodName   = "/wethertweet.WeatherStatsService/GetConditionCounts"
+	WeatherStatsService_GetMunicipalityStats_FullMethodName = "/wethertweet.WeatherStatsService/GetMunicipalityStats"
+	WeatherStatsService_GetTimeSeries_FullMethodName        = "/wethertweet.WeatherStatsService/GetTimeSeries"
+	WeatherStatsService_WatchStats_FullMethodName           = "/wethertweet.WeatherStatsService/WatchStats"
+	WeatherStatsService_ListRecentTweets_FullMethodName     = "/wethertweet.WeatherStatsService/ListRecentTweets"
+)
+
+// WeatherStatsServiceClient is the client API for WeatherStatsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Servicio gRPC de consulta de estadísticas
+type WeatherStatsServiceClient interface {
+	GetConditionCounts(ctx context.Context, in *ConditionCountsRequest, opts ...grpc.CallOption) (*ConditionCountsResponse, error)
+	GetMunicipalityStats(ctx context.Context, in *MunicipalityStatsRequest, opts ...grpc.CallOption) (*MunicipalityStatsResponse, error)
+	GetTimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (*TimeSeriesResponse, error)
+	WatchStats(ctx context.Context, in *WatchStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatsUpdate], error)
+	ListRecentTweets(ctx context.Context, in *ListRecentTweetsRequest, opts ...grpc.CallOption) (*ListRecentTweetsResponse, error)
+}
+
+type weatherStatsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWeatherStatsServiceClient(cc grpc.ClientConnInterface) WeatherStatsServiceClient {
+	return &weatherStatsServiceClient{cc}
+}
+
+func (c *weatherStatsServiceClient) GetConditionCounts(ctx context.Context, in *ConditionCountsRequest, opts ...grpc.CallOption) (*ConditionCountsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConditionCountsResponse)
+	err := c.cc.Invoke(ctx, WeatherStatsService_GetConditionCounts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherStatsServiceClient) GetMunicipalityStats(ctx context.Context, in *MunicipalityStatsRequest, opts ...grpc.CallOption) (*MunicipalityStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MunicipalityStatsResponse)
+	err := c.cc.Invoke(ctx, WeatherStatsService_GetMunicipalityStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherStatsServiceClient) GetTimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (*TimeSeriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TimeSeriesResponse)
+	err := c.cc.Invoke(ctx, WeatherStatsService_GetTimeSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherStatsServiceClient) WatchStats(ctx context.Context, in *WatchStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatsUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WeatherStatsService_ServiceDesc.Streams[0], WeatherStatsService_WatchStats_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchStatsRequest, StatsUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherStatsService_WatchStatsClient = grpc.ServerStreamingClient[StatsUpdate]
+
+func (c *weatherStatsServiceClient) ListRecentTweets(ctx context.Context, in *ListRecentTweetsRequest, opts ...grpc.CallOption) (*ListRecentTweetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRecentTweetsResponse)
+	err := c.cc.Invoke(ctx, WeatherStatsService_ListRecentTweets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WeatherStatsServiceServer is the server API for WeatherStatsService service.
+// All implementations must embed UnimplementedWeatherStatsServiceServer
+// for forward compatibility.
+//
+// Servicio gRPC de consulta de estadísticas
+type WeatherStatsServiceServer interface {
+	GetConditionCounts(context.Context, *ConditionCountsRequest) (*ConditionCountsResponse, error)
+	GetMunicipalityStats(context.Context, *MunicipalityStatsRequest) (*MunicipalityStatsResponse, error)
+	GetTimeSeries(context.Context, *TimeSeriesRequest) (*TimeSeriesResponse, error)
+	WatchStats(*WatchStatsRequest, grpc.ServerStreamingServer[StatsUpdate]) error
+	ListRecentTweets(context.Context, *ListRecentTweetsRequest) (*ListRecentTweetsResponse, error)
+	mustEmbedUnimplementedWeatherStatsServiceServer()
+}
+
+// UnimplementedWeatherStatsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWeatherStatsServiceServer struct{}
+
+func (UnimplementedWeatherStatsServiceServer) GetConditionCounts(context.Context, *ConditionCountsRequest) (*ConditionCountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConditionCounts not implemented")
+}
+func (UnimplementedWeatherStatsServiceServer) GetMunicipalityStats(context.Context, *MunicipalityStatsRequest) (*MunicipalityStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMunicipalityStats not implemented")
+}
+func (UnimplementedWeatherStatsServiceServer) GetTimeSeries(context.Context, *TimeSeriesRequest) (*TimeSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTimeSeries not implemented")
+}
+func (UnimplementedWeatherStatsServiceServer) WatchStats(*WatchStatsRequest, grpc.ServerStreamingServer[StatsUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStats not implemented")
+}
+func (UnimplementedWeatherStatsServiceServer) ListRecentTweets(context.Context, *ListRecentTweetsRequest) (*ListRecentTweetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecentTweets not implemented")
+}
+func (UnimplementedWeatherStatsServiceServer) mustEmbedUnimplementedWeatherStatsServiceServer() {}
+func (UnimplementedWeatherStatsServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeWeatherStatsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WeatherStatsServiceServer will
+// result in compilation errors.
+type UnsafeWeatherStatsServiceServer interface {
+	mustEmbedUnimplementedWeatherStatsServiceServer()
+}
+
+func RegisterWeatherStatsServiceServer(s grpc.ServiceRegistrar, srv WeatherStatsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWeatherStatsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WeatherStatsService_ServiceDesc, srv)
+}
+
+func _WeatherStatsService_GetConditionCounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConditionCountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherStatsServiceServer).GetConditionCounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherStatsService_GetConditionCounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherStatsServiceServer).GetConditionCounts(ctx, req.(*ConditionCountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherStatsService_GetMunicipalityStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MunicipalityStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherStatsServiceServer).GetMunicipalityStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherStatsService_GetMunicipalityStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherStatsServiceServer).GetMunicipalityStats(ctx, req.(*MunicipalityStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherStatsService_GetTimeSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimeSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherStatsServiceServer).GetTimeSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherStatsService_GetTimeSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherStatsServiceServer).GetTimeSeries(ctx, req.(*TimeSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherStatsService_WatchStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WeatherStatsServiceServer).WatchStats(m, &grpc.GenericServerStream[WatchStatsRequest, StatsUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherStatsService_WatchStatsServer = grpc.ServerStreamingServer[StatsUpdate]
+
+func _WeatherStatsService_ListRecentTweets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecentTweetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherStatsServiceServer).ListRecentTweets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherStatsService_ListRecentTweets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherStatsServiceServer).ListRecentTweets(ctx, req.(*ListRecentTweetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WeatherStatsService_ServiceDesc is the grpc.ServiceDesc for WeatherStatsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WeatherStatsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wethertweet.WeatherStatsService",
+	HandlerType: (*WeatherStatsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConditionCounts",
+			Handler:    _WeatherStatsService_GetConditionCounts_Handler,
+		},
+		{
+			MethodName: "GetMunicipalityStats",
+			Handler:    _WeatherStatsService_GetMunicipalityStats_Handler,
+		},
+		{
+			MethodName: "GetTimeSeries",
+			Handler:    _WeatherStatsService_GetTimeSeries_Handler,
+		},
+		{
+			MethodName: "ListRecentTweets",
+			Handler:    _WeatherStatsService_ListRecentTweets_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStats",
+			Handler:       _WeatherStatsService_WatchStats_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/weather_tweet.proto",
+}
@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+
+	"go-services/health"
+	"go-services/latency"
+	"go-services/messaging"
+	"go-services/storage"
+	"go-services/workerpool"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/streadway/amqp"
@@ -13,12 +19,10 @@ import (
 
 var ctx = context.Background()
 
-type Tweet struct {
-	Municipality int32 `json:"municipality"`
-	Temperature  int32 `json:"temperature"`
-	Humidity     int32 `json:"humidity"`
-	Weather      int32 `json:"weather"`
-}
+const consumerTag = "rabbitmq-consumer"
+
+// probes backs the /healthz, /readyz and /status endpoints.
+var probes *health.Server
 
 func main() {
 	rabbitMQURL := os.Getenv("RABBITMQ_URL")
@@ -31,82 +35,230 @@ func main() {
 		valkeyAddr = "valkey:6379"
 	}
 
+	prefetch := storage.GetEnvInt("RABBITMQ_PREFETCH", 10)
+	maxRetries := storage.GetEnvInt("VALKEY_MAX_RETRIES", 5)
+	maxDeliveries := int64(messaging.MaxDeliveries)
+	workers := storage.GetEnvInt("WORKERS", 4)
+
+	healthAddr := os.Getenv("HEALTH_ADDR")
+	if healthAddr == "" {
+		healthAddr = ":8080"
+	}
+
 	rdb := redis.NewClient(&redis.Options{
 		Addr: valkeyAddr,
 	})
 
-	conn, err := amqp.Dial(rabbitMQURL)
-	failOnError(err, "Failed to connect to RabbitMQ")
-	defer conn.Close()
-
-	ch, err := conn.Channel()
-	failOnError(err, "Failed to open a channel")
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(
-		"weather-tweets", // name
-		true,             // durable
-		false,            // delete when unused
-		false,            // exclusive
-		false,            // no-wait
-		nil,              // arguments
-	)
-	failOnError(err, "Failed to declare a queue")
-
-	msgs, err := ch.Consume(
-		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
-	failOnError(err, "Failed to register a consumer")
-
-	forever := make(chan bool)
+	probes = health.New("rabbitmq", rdb)
+	probes.ListenAndServe(healthAddr)
 
+	store := storage.New(rdb, storage.SourceRabbitMQ)
+	store.ObserveLatency(probes.ObserveLatency)
+	if err := store.Init(ctx); err != nil {
+		log.Fatalf("Failed to prepare Valkey: %v", err)
+	}
+
+	// The prefetch count bounds the deliveries in flight, so each worker
+	// only needs room for what the broker can hand out.
+	pool := workerpool.New(workers, prefetch)
+
+	stop := make(chan struct{})
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
-		for d := range msgs {
-			log.Printf("Received from RabbitMQ: %s", d.Body)
-			var tweet Tweet
-			if err := json.Unmarshal(d.Body, &tweet); err != nil {
-				log.Printf("Error unmarshalling tweet: %v", err)
-				continue
-			}
+		sig := <-sigchan
+		log.Printf("Caught signal %v: shutting down", sig)
+		close(stop)
+	}()
+
+	log.Printf(" [*] Waiting for messages. To exit press CTRL+C")
+	for {
+		s, ok := dialWithBackoff(rabbitMQURL, prefetch, stop)
+		if !ok {
+			pool.Close()
+			break
+		}
+		if !consume(s, pool, store, maxRetries, maxDeliveries, stop) {
+			// Wait for the workers while the channel is still open so
+			// their acks reach the broker.
+			pool.Close()
+			s.close()
+			break
+		}
+		probes.SetConnected(false)
+		probes.SetAssignments(nil)
+		s.close()
+	}
 
-			// Store in Valkey
-			weatherCondition := getWeatherCondition(tweet.Weather)
-			err = rdb.Incr(ctx, fmt.Sprintf("weather:%s", weatherCondition)).Err()
-			if err != nil {
-				log.Printf("Failed to increment weather condition count in Valkey: %v", err)
-			} else {
-				log.Printf("Incremented count for weather: %s", weatherCondition)
+	rdb.Close()
+	log.Printf("RabbitMQ consumer stopped")
+}
+
+// consume hands deliveries to the pool until the session breaks, in which
+// case it returns true so the caller reconnects, or until stop is closed.
+func consume(s *session, pool *workerpool.Pool, store *storage.Store, maxRetries int, maxDeliveries int64, stop <-chan struct{}) bool {
+	for {
+		select {
+		case d, ok := <-s.msgs:
+			if !ok {
+				log.Printf("Delivery channel closed, reconnecting")
+				return true
+			}
+			submit(pool, store, s.ch, d, maxRetries, maxDeliveries)
+		case err := <-s.connClosed:
+			log.Printf("Connection to RabbitMQ closed, reconnecting: %v", err)
+			return true
+		case err := <-s.chClosed:
+			log.Printf("RabbitMQ channel closed, reconnecting: %v", err)
+			return true
+		case <-stop:
+			// Cancelling stops new deliveries; msgs is closed once the
+			// ones already received have been handed over. Unacked
+			// prefetched messages are requeued by the broker.
+			if err := s.ch.Cancel(consumerTag, false); err != nil {
+				log.Printf("Failed to cancel consumer: %v", err)
 			}
+			for d := range s.msgs {
+				submit(pool, store, s.ch, d, maxRetries, maxDeliveries)
+			}
+			return false
 		}
-	}()
+	}
+}
 
-	log.Printf(" [*] Waiting for messages. To exit press CTRL+C")
-	<-forever
+// submit decodes d once and hands it to the pool, keyed by municipality. A
+// delivery that doesn't decode is handed over with the error, for
+// handleDelivery to dead-letter.
+func submit(pool *workerpool.Pool, store *storage.Store, ch *amqp.Channel, d amqp.Delivery, maxRetries int, maxDeliveries int64) {
+	tweet, decodeErr := messaging.DecodeAMQPTweet(d)
+	pool.Submit(messaging.OrderingKey(tweet, decodeErr), func() {
+		handleDelivery(store, ch, d, tweet, decodeErr, maxRetries, maxDeliveries)
+	})
 }
 
-func failOnError(err error, msg string) {
+// handleDelivery stores a tweet and settles the delivery: ack once Valkey has
+// it, requeue when storing still fails after maxRetries attempts and
+// dead-letter malformed bodies or messages that keep failing. Retrying here
+// first keeps a short Valkey outage from using up the delivery limit.
+func handleDelivery(store *storage.Store, ch *amqp.Channel, d amqp.Delivery, tweet messaging.Tweet, decodeErr error, maxRetries int, maxDeliveries int64) {
+	attempts := deliveryAttempts(d)
+	log.Printf("Received from RabbitMQ (attempt %d): %s", attempts, d.Body)
+	if attempts == 1 {
+		publishedAt, ok := toInt64(d.Headers[messaging.HeaderPublishedAt])
+		if !ok && !d.Timestamp.IsZero() {
+			publishedAt = d.Timestamp.UnixMilli()
+		}
+		store.RecordLatency(ctx, latency.StagePublishToConsume, publishedAt)
+	}
+	if decodeErr != nil {
+		log.Printf("Error unmarshalling tweet: %v", decodeErr)
+		deadLetter(store, ch, d, messaging.ReasonMalformed, decodeErr, attempts)
+		return
+	}
+
+	if tweet.ID == "" {
+		tweet.ID = d.MessageId
+	}
+	if tweet.ReceivedAt == 0 {
+		tweet.ReceivedAt, _ = toInt64(d.Headers[messaging.HeaderReceivedAt])
+	}
+
+	// Store in Valkey
+	weatherCondition := tweet.ConditionName()
+	stored := false
+	_, err := storage.Retry(maxRetries, func() error {
+		var err error
+		stored, err = store.Record(ctx, storage.Tweet{
+			ID:           tweet.ID,
+			Municipality: tweet.MunicipalityName(),
+			Condition:    weatherCondition,
+			Temperature:  tweet.Temperature,
+			Humidity:     tweet.Humidity,
+			ReceivedAt:   tweet.ReceivedTime(),
+		})
+		return err
+	})
 	if err != nil {
-		log.Fatalf("%s: %s", msg, err)
+		log.Printf("Failed to increment weather condition count in Valkey: %v", err)
+		probes.RecordError(messaging.ReasonStorageFailed)
+		if attempts >= maxDeliveries {
+			deadLetter(store, ch, d, messaging.ReasonMaxDeliveries, err, attempts)
+			return
+		}
+		// Not counted as failed yet: the redelivery may still succeed.
+		if err := d.Nack(false, true); err != nil {
+			log.Printf("Failed to requeue message: %v", err)
+		}
+		return
+	}
+	if stored {
+		log.Printf("Incremented count for weather: %s", weatherCondition)
+		probes.MessageProcessed()
+		store.RecordLatency(ctx, latency.StageReceiveToStored, tweet.ReceivedAt)
+	} else {
+		log.Printf("Skipping tweet %s: already stored", tweet.ID)
+	}
+	if err := d.Ack(false); err != nil {
+		log.Printf("Failed to ack message: %v", err)
+	}
+}
+
+// deadLetter moves d to the dead-letter queue with the failure reason. If the
+// publish fails the message is rejected instead, which still routes it to the
+// dead-letter exchange, only without the failure headers.
+func deadLetter(store *storage.Store, ch *amqp.Channel, d amqp.Delivery, reason string, cause error, attempts int64) {
+	probes.RecordError(reason)
+	store.Count(ctx, storage.EventFailed)
+	if err := messaging.DeadLetter(ch, d, reason, cause, attempts); err != nil {
+		log.Printf("Failed to publish to dead-letter exchange: %v", err)
+		if err := d.Nack(false, false); err != nil {
+			log.Printf("Failed to reject message: %v", err)
+		}
+		return
+	}
+	log.Printf("Dead-lettered message (%s) after %d attempts", reason, attempts)
+	store.Count(ctx, storage.EventDeadLettered)
+	if err := d.Ack(false); err != nil {
+		log.Printf("Failed to ack message: %v", err)
+	}
+}
+
+// deliveryAttempts returns how many times this message has been delivered.
+// Quorum queues count redeliveries in x-delivery-count; the x-death history
+// and the Redelivered flag cover messages coming from other queue types.
+func deliveryAttempts(d amqp.Delivery) int64 {
+	attempts := int64(1)
+	if count, ok := toInt64(d.Headers["x-delivery-count"]); ok {
+		return attempts + count
+	}
+	if deaths, ok := d.Headers["x-death"].([]interface{}); ok {
+		for _, death := range deaths {
+			table, ok := death.(amqp.Table)
+			if !ok {
+				continue
+			}
+			if count, ok := toInt64(table["count"]); ok {
+				attempts += count
+			}
+		}
+	}
+	if attempts == 1 && d.Redelivered {
+		attempts++
 	}
+	return attempts
 }
 
-func getWeatherCondition(weather int32) string {
-	switch weather {
-	case 1:
-		return "sunny"
-	case 2:
-		return "cloudy"
-	case 3:
-		return "rainy"
-	case 4:
-		return "foggy"
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int8:
+		return int64(n), true
 	default:
-		return "unknown"
+		return 0, false
 	}
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"go-services/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+// session is one live connection to RabbitMQ with its consuming channel.
+type session struct {
+	conn       *amqp.Connection
+	ch         *amqp.Channel
+	msgs       <-chan amqp.Delivery
+	connClosed chan *amqp.Error
+	chClosed   chan *amqp.Error
+}
+
+// dial connects to RabbitMQ, declares the topology and starts consuming.
+func dial(url string, prefetch int) (*session, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	q, err := messaging.DeclareRabbitMQ(ch)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	err = ch.Qos(
+		prefetch, // prefetch count
+		0,        // prefetch size
+		false,    // global
+	)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	msgs, err := ch.Consume(
+		q.Name,      // queue
+		consumerTag, // consumer
+		false,       // auto-ack
+		false,       // exclusive
+		false,       // no-local
+		false,       // no-wait
+		nil,         // args
+	)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &session{
+		conn:       conn,
+		ch:         ch,
+		msgs:       msgs,
+		connClosed: conn.NotifyClose(make(chan *amqp.Error, 1)),
+		chClosed:   ch.NotifyClose(make(chan *amqp.Error, 1)),
+	}, nil
+}
+
+// dialWithBackoff keeps dialing until it succeeds or stop is closed, waiting
+// exponentially longer between attempts with some jitter so several replicas
+// don't hammer a restarting broker in lockstep.
+func dialWithBackoff(url string, prefetch int, stop <-chan struct{}) (*session, bool) {
+	backoff := 500 * time.Millisecond
+	for {
+		s, err := dial(url, prefetch)
+		if err == nil {
+			probes.SetConnected(true)
+			probes.SetSubscribed(true)
+			probes.SetAssignments([]string{messaging.TweetsQueue})
+			log.Printf("Connected to RabbitMQ")
+			return s, true
+		}
+		probes.SetConnected(false)
+		probes.RecordError("connect")
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.Printf("Failed to connect to RabbitMQ, retrying in %v: %v", wait, err)
+		select {
+		case <-time.After(wait):
+		case <-stop:
+			return nil, false
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (s *session) close() {
+	s.ch.Close()
+	s.conn.Close()
+}
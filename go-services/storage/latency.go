@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"go-services/latency"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Resolution is the width of the time buckets that latency histograms,
+// event stats, time series and reconciliation data are kept in.
+const Resolution = time.Minute
+
+const defaultLatencyRetention = 7 * 24 * time.Hour
+
+// LatencyKey is the hash holding the latency histogram of stage for source
+// during the minute that contains t. Fields are bucket upper bounds in
+// microseconds, values are counts.
+func LatencyKey(source, stage string, t time.Time) string {
+	return "latency:" + source + ":" + stage + ":" + strconv.FormatInt(t.Truncate(Resolution).Unix(), 10)
+}
+
+// LatencyBucketKey is the same histogram for the bucket of width, one of
+// SeriesBuckets, that contains t.
+func LatencyBucketKey(source, stage string, width time.Duration, t time.Time) string {
+	name := bucketName(width)
+	if name == "" {
+		return LatencyKey(source, stage, t)
+	}
+	return "latency:" + name + source + ":" + stage + ":" + strconv.FormatInt(t.Truncate(width).Unix(), 10)
+}
+
+// ObserveLatency makes the Store also pass every latency it records to
+// observe, e.g. for the consumer's /status endpoint.
+func (s *Store) ObserveLatency(observe func(stage string, d time.Duration)) {
+	s.observeLatency = observe
+}
+
+// RecordLatency adds how long stage took since start, a Unix millisecond
+// timestamp stamped by the gRPC server, to the histograms of the current
+// minute, hour and day. Messages without one are skipped. Latencies are best effort, so
+// failures are only logged.
+func (s *Store) RecordLatency(ctx context.Context, stage string, start int64) {
+	if start <= 0 {
+		return
+	}
+	d := time.Since(time.UnixMilli(start))
+	if s.observeLatency != nil {
+		s.observeLatency(stage, d)
+	}
+	now := time.Now()
+	bucket := strconv.FormatInt(latency.Bucket(d), 10)
+	pipe := s.rdb.Pipeline()
+	for _, width := range SeriesBuckets {
+		key := LatencyBucketKey(s.source, stage, width, now)
+		pipe.HIncrBy(ctx, key, bucket, 1)
+		pipe.Expire(ctx, key, s.latencyRetention)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to record %s latency: %v", stage, err)
+	}
+}
+
+// ReadLatency merges the histograms of stage for source between from and to.
+func ReadLatency(ctx context.Context, rdb *redis.Client, source, stage string, from, to time.Time) (latency.Histogram, error) {
+	pipe := rdb.Pipeline()
+	var cmds []*redis.StringStringMapCmd
+	for t := from.Truncate(Resolution); !t.After(to); t = t.Add(Resolution) {
+		cmds = append(cmds, pipe.HGetAll(ctx, LatencyKey(source, stage, t)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	h := make(latency.Histogram)
+	for _, cmd := range cmds {
+		h.Merge(latency.FromStrings(cmd.Val()))
+	}
+	return h, nil
+}
+
+// LatencyPoint holds the latency histogram of one time bucket.
+type LatencyPoint struct {
+	Start     time.Time
+	Histogram latency.Histogram
+}
+
+// ReadLatencySeries returns the histograms of stage for source between from
+// and to, oldest first, in buckets as in ReadSeries.
+func ReadLatencySeries(ctx context.Context, rdb *redis.Client, source, stage string, from, to time.Time, resolution time.Duration) ([]LatencyPoint, error) {
+	resolution = resolution.Truncate(Resolution)
+	if resolution < Resolution {
+		resolution = Resolution
+	}
+	width := seriesBucket(resolution)
+	starts, hashes, err := readBuckets(ctx, rdb, from, to, width, func(t time.Time) string {
+		return LatencyBucketKey(source, stage, width, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var points []LatencyPoint
+	for i, fields := range hashes {
+		start := starts[i].Truncate(resolution)
+		if len(points) == 0 || !points[len(points)-1].Start.Equal(start) {
+			points = append(points, LatencyPoint{Start: start, Histogram: make(latency.Histogram)})
+		}
+		points[len(points)-1].Histogram.Merge(latency.FromStrings(fields))
+	}
+	return points, nil
+}
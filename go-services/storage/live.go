@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// LiveStreamKey is the stream every tweet is appended to once, by the first
+// broker that stores it. It is capped at about LIVE_STREAM_LENGTH entries.
+const LiveStreamKey = "stream:tweets"
+
+const defaultLiveStreamLength = 10000
+
+// LiveTweet is an entry of the live stream.
+type LiveTweet struct {
+	// EventID is the stream entry ID, to resume reading after it.
+	EventID      string `json:"event_id"`
+	ID           string `json:"id"`
+	Source       string `json:"source"`
+	Municipality string `json:"municipality"`
+	Condition    string `json:"condition"`
+	Temperature  int64  `json:"temperature"`
+	Humidity     int64  `json:"humidity"`
+	// ReceivedAt is when the gRPC server received the tweet (Unix
+	// milliseconds).
+	ReceivedAt int64 `json:"received_at"`
+}
+
+func parseLiveTweet(msg redis.XMessage) LiveTweet {
+	field := func(name string) string {
+		v, _ := msg.Values[name].(string)
+		return v
+	}
+	number := func(name string) int64 {
+		n, _ := strconv.ParseInt(field(name), 10, 64)
+		return n
+	}
+	return LiveTweet{
+		EventID:      msg.ID,
+		ID:           field("id"),
+		Source:       field("source"),
+		Municipality: field("municipality"),
+		Condition:    field("condition"),
+		Temperature:  number("temperature"),
+		Humidity:     number("humidity"),
+		ReceivedAt:   number("received_at"),
+	}
+}
+
+// ReadLive returns up to count tweets appended after the entry lastID, "$"
+// meaning only new ones, waiting up to block for the first one. It returns no
+// tweets, and no error, if none arrived in time.
+func ReadLive(ctx context.Context, rdb *redis.Client, lastID string, count int64, block time.Duration) ([]LiveTweet, error) {
+	streams, err := rdb.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{LiveStreamKey, lastID},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tweets []LiveTweet
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			tweets = append(tweets, parseLiveTweet(msg))
+		}
+	}
+	return tweets, nil
+}
+
+// LastLiveID returns the ID of the newest entry of the live stream, or "0-0"
+// if it is empty, so readers starting now don't miss what comes next.
+func LastLiveID(ctx context.Context, rdb *redis.Client) (string, error) {
+	msgs, err := rdb.XRevRangeN(ctx, LiveStreamKey, "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(msgs) == 0 {
+		return "0-0", nil
+	}
+	return msgs[0].ID, nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultRecentLength    = 10000
+	defaultRecentRetention = 24 * time.Hour
+)
+
+// RecentKey is the stream of the tweets of municipality as each broker
+// stored them, so a tweet delivered by both brokers appears twice. Entries
+// older than RECENT_TWEETS_RETENTION, or beyond about RECENT_TWEETS_LENGTH,
+// are trimmed.
+func RecentKey(municipality string) string {
+	return "recent:" + municipality
+}
+
+// ErrInvalidCursor is returned for a before entry ID that ListRecent never
+// handed out.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// RecentTweet is an entry of a recent tweets stream.
+type RecentTweet struct {
+	// EventID is the stream entry ID, which starts with the Unix
+	// millisecond the tweet was stored.
+	EventID     string
+	ID          string
+	Source      string
+	Condition   string
+	Temperature int64
+	Humidity    int64
+	// ReceivedAt is when the gRPC server received the tweet (Unix
+	// milliseconds).
+	ReceivedAt int64
+}
+
+// StoredAt returns when the tweet was stored, taken from its entry ID.
+func (t RecentTweet) StoredAt() time.Time {
+	ms, _ := strconv.ParseInt(strings.SplitN(t.EventID, "-", 2)[0], 10, 64)
+	return time.UnixMilli(ms)
+}
+
+// ListRecent returns up to count tweets of municipality, newest first,
+// starting before the entry before, or with the newest one if before is
+// empty. Only tweets stored by source are returned, unless it is
+// SourceCombined. It also returns the entry ID to pass as before for the next
+// page, empty once there are no more tweets.
+func ListRecent(ctx context.Context, rdb *redis.Client, municipality, source string, before string, count int64) ([]RecentTweet, string, error) {
+	end := "+"
+	if before != "" {
+		if !validStreamID(before) {
+			return nil, "", ErrInvalidCursor
+		}
+		end = "(" + before
+	}
+
+	// One entry more than a page tells whether there is a next one.
+	var tweets []RecentTweet
+	for {
+		msgs, err := rdb.XRevRangeN(ctx, RecentKey(municipality), end, "-", count+1).Result()
+		if err != nil {
+			return nil, "", err
+		}
+		for _, msg := range msgs {
+			t := parseRecentTweet(msg)
+			if source != SourceCombined && t.Source != source {
+				continue
+			}
+			if int64(len(tweets)) == count {
+				return tweets, tweets[len(tweets)-1].EventID, nil
+			}
+			tweets = append(tweets, t)
+		}
+		if int64(len(msgs)) <= count {
+			return tweets, "", nil
+		}
+		end = "(" + msgs[len(msgs)-1].ID
+	}
+}
+
+// validStreamID reports whether id is a complete stream entry ID.
+func validStreamID(id string) bool {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	_, msErr := strconv.ParseUint(ms, 10, 64)
+	_, seqErr := strconv.ParseUint(seq, 10, 64)
+	return msErr == nil && seqErr == nil
+}
+
+func parseRecentTweet(msg redis.XMessage) RecentTweet {
+	field := func(name string) string {
+		v, _ := msg.Values[name].(string)
+		return v
+	}
+	number := func(name string) int64 {
+		n, _ := strconv.ParseInt(field(name), 10, 64)
+		return n
+	}
+	return RecentTweet{
+		EventID:     msg.ID,
+		ID:          field("id"),
+		Source:      field("source"),
+		Condition:   field("condition"),
+		Temperature: number("temperature"),
+		Humidity:    number("humidity"),
+		ReceivedAt:  number("received_at"),
+	}
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TweetsKey is the hash of tweet IDs source stored for tweets received during
+// the minute containing t. Values are
+// "<condition>:<receive-to-stored ms>:<municipality>:<temperature>:<humidity>".
+func TweetsKey(source string, t time.Time) string {
+	return "tweets:" + source + ":" + strconv.FormatInt(t.Truncate(Resolution).Unix(), 10)
+}
+
+// DuplicatesKey is the hash counting, per tweet ID, how many redeliveries of
+// a tweet received during the minute containing t source ignored.
+func DuplicatesKey(source string, t time.Time) string {
+	return "dups:" + source + ":" + strconv.FormatInt(t.Truncate(Resolution).Unix(), 10)
+}
+
+// repairedMarker replaces the latency of IDs added by a repair.
+const repairedMarker = "repaired"
+
+// TweetRecord is one entry of a TweetsKey hash.
+type TweetRecord struct {
+	Condition string
+	// Latency from receipt to storage; zero for repaired or legacy entries.
+	Latency  time.Duration
+	Repaired bool
+	// Municipality, Temperature and Humidity are empty in entries written
+	// before they were recorded.
+	Municipality string
+	Temperature  int32
+	Humidity     int32
+}
+
+// Tweet returns the tweet r describes, filed under minute, for repairing the
+// view of a broker that lost it with RecordBatch.
+func (r TweetRecord) Tweet(id string, minute time.Time) Tweet {
+	municipality := r.Municipality
+	if municipality == "" {
+		municipality = "unknown"
+	}
+	return Tweet{
+		ID:           id,
+		Municipality: municipality,
+		Condition:    r.Condition,
+		Temperature:  r.Temperature,
+		Humidity:     r.Humidity,
+		ReceivedAt:   minute,
+		Repaired:     true,
+	}
+}
+
+func tweetRecordValue(t Tweet, latency time.Duration) string {
+	stored := strconv.FormatInt(latency.Milliseconds(), 10)
+	if t.Repaired {
+		stored = repairedMarker
+	}
+	return strings.Join([]string{
+		t.Condition,
+		stored,
+		t.Municipality,
+		strconv.Itoa(int(t.Temperature)),
+		strconv.Itoa(int(t.Humidity)),
+	}, ":")
+}
+
+func parseTweetRecord(value string) TweetRecord {
+	parts := strings.Split(value, ":")
+	r := TweetRecord{Condition: parts[0]}
+	if len(parts) > 1 {
+		if parts[1] == repairedMarker {
+			r.Repaired = true
+		} else if ms, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+			r.Latency = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if len(parts) == 5 {
+		r.Municipality = parts[2]
+		if n, err := strconv.ParseInt(parts[3], 10, 32); err == nil {
+			r.Temperature = int32(n)
+		}
+		if n, err := strconv.ParseInt(parts[4], 10, 32); err == nil {
+			r.Humidity = int32(n)
+		}
+	}
+	return r
+}
+
+// ReadTweets returns the IDs source stored for the minute starting at minute.
+func ReadTweets(ctx context.Context, rdb *redis.Client, source string, minute time.Time) (map[string]TweetRecord, error) {
+	fields, err := rdb.HGetAll(ctx, TweetsKey(source, minute)).Result()
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]TweetRecord, len(fields))
+	for id, value := range fields {
+		records[id] = parseTweetRecord(value)
+	}
+	return records, nil
+}
+
+// ReadDuplicates returns how many extra copies of each ID source stored for
+// the minute starting at minute.
+func ReadDuplicates(ctx context.Context, rdb *redis.Client, source string, minute time.Time) (map[string]int64, error) {
+	fields, err := rdb.HGetAll(ctx, DuplicatesKey(source, minute)).Result()
+	if err != nil {
+		return nil, err
+	}
+	dups := make(map[string]int64, len(fields))
+	for id, value := range fields {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			dups[id] = n
+		}
+	}
+	return dups, nil
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MunicipalityStats aggregates the tweets of a municipality hash.
+type MunicipalityStats struct {
+	Count          int64
+	TemperatureSum int64
+	HumiditySum    int64
+	// Conditions counts the tweets per weather condition.
+	Conditions map[string]int64
+}
+
+// AverageTemperature returns the mean temperature, zero without tweets.
+func (m MunicipalityStats) AverageTemperature() float64 {
+	if m.Count == 0 {
+		return 0
+	}
+	return float64(m.TemperatureSum) / float64(m.Count)
+}
+
+// AverageHumidity returns the mean humidity, zero without tweets.
+func (m MunicipalityStats) AverageHumidity() float64 {
+	if m.Count == 0 {
+		return 0
+	}
+	return float64(m.HumiditySum) / float64(m.Count)
+}
+
+// Merge adds the tweets of o to m.
+func (m *MunicipalityStats) Merge(o MunicipalityStats) {
+	m.Count += o.Count
+	m.TemperatureSum += o.TemperatureSum
+	m.HumiditySum += o.HumiditySum
+	for condition, n := range o.Conditions {
+		if m.Conditions == nil {
+			m.Conditions = make(map[string]int64)
+		}
+		m.Conditions[condition] += n
+	}
+}
+
+func parseMunicipalityStats(fields map[string]string) MunicipalityStats {
+	m := MunicipalityStats{Conditions: make(map[string]int64)}
+	for field, value := range fields {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch field {
+		case FieldCount:
+			m.Count = n
+		case FieldTemperatureSum:
+			m.TemperatureSum = n
+		case FieldHumiditySum:
+			m.HumiditySum = n
+		default:
+			if condition := strings.TrimPrefix(field, "weather:"); condition != field {
+				m.Conditions[condition] = n
+			}
+		}
+	}
+	return m
+}
+
+// ReadMunicipalityStats returns the lifetime stats of municipalities in the
+// view of source. Municipalities without tweets are left out.
+func ReadMunicipalityStats(ctx context.Context, rdb *redis.Client, source string, municipalities []string) (map[string]MunicipalityStats, error) {
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, len(municipalities))
+	for i, municipality := range municipalities {
+		cmds[i] = pipe.HGetAll(ctx, MunicipalityKey(source, municipality))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	stats := make(map[string]MunicipalityStats, len(municipalities))
+	for i, cmd := range cmds {
+		if fields := cmd.Val(); len(fields) > 0 {
+			stats[municipalities[i]] = parseMunicipalityStats(fields)
+		}
+	}
+	return stats, nil
+}
+
+// SeriesPoint holds the tweets of one time bucket.
+type SeriesPoint struct {
+	Start time.Time
+	MunicipalityStats
+}
+
+// SeriesBuckets are the widths of the series hashes, coarsest first. Every
+// tweet is added to a bucket of each width, so long ranges are read from a
+// few hour or day buckets instead of one key per minute.
+var SeriesBuckets = []time.Duration{24 * time.Hour, time.Hour, Resolution}
+
+// MaxSeriesBuckets bounds how many buckets per municipality ReadSeries reads:
+// a day of minutes, or two months of hours.
+const MaxSeriesBuckets = 1440
+
+// ErrSeriesTooLong is returned by ReadSeries for ranges that need more than
+// MaxSeriesBuckets buckets at the requested resolution.
+var ErrSeriesTooLong = errors.New("too many buckets for the time range")
+
+// seriesBucket returns the widest bucket resolution is a multiple of.
+func seriesBucket(resolution time.Duration) time.Duration {
+	for _, width := range SeriesBuckets {
+		if resolution%width == 0 {
+			return width
+		}
+	}
+	return Resolution
+}
+
+// SeriesResolution rounds resolution up, to a multiple of Resolution and
+// then of an hour or a day if needed, until ReadSeries can serve the range
+// from to to.
+func SeriesResolution(from, to time.Time, resolution time.Duration) time.Duration {
+	resolution = roundUp(resolution, Resolution)
+	for i := len(SeriesBuckets) - 1; i >= 0; i-- {
+		width := SeriesBuckets[i]
+		resolution = roundUp(resolution, width)
+		if seriesBuckets(from, to, seriesBucket(resolution)) <= MaxSeriesBuckets {
+			break
+		}
+	}
+	return resolution
+}
+
+func roundUp(d, unit time.Duration) time.Duration {
+	if d < unit {
+		return unit
+	}
+	return (d + unit - 1).Truncate(unit)
+}
+
+// seriesBuckets returns how many buckets of width cover from to to.
+func seriesBuckets(from, to time.Time, width time.Duration) int {
+	if to.Before(from) {
+		return 0
+	}
+	return int(to.Truncate(width).Sub(from.Truncate(width))/width) + 1
+}
+
+// readBuckets reads the hash key returns for every bucket of width between
+// from and to, oldest first, after checking the range against
+// MaxSeriesBuckets.
+func readBuckets(ctx context.Context, rdb *redis.Client, from, to time.Time, width time.Duration, key func(time.Time) string) ([]time.Time, []map[string]string, error) {
+	if seriesBuckets(from, to, width) > MaxSeriesBuckets {
+		return nil, nil, ErrSeriesTooLong
+	}
+	pipe := rdb.Pipeline()
+	var starts []time.Time
+	var cmds []*redis.StringStringMapCmd
+	for t := from.Truncate(width); !t.After(to); t = t.Add(width) {
+		starts = append(starts, t)
+		cmds = append(cmds, pipe.HGetAll(ctx, key(t)))
+	}
+	if len(cmds) == 0 {
+		return nil, nil, nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, nil, err
+	}
+	hashes := make([]map[string]string, len(cmds))
+	for i, cmd := range cmds {
+		hashes[i] = cmd.Val()
+	}
+	return starts, hashes, nil
+}
+
+// ReadSeries returns the tweets of municipalities, added together, in the
+// view of source between from and to, oldest first. Buckets are resolution
+// wide, which is rounded down to a multiple of Resolution, and are returned
+// even when empty. They are read from the widest series hashes resolution is
+// a multiple of, so a bucket can include tweets from just before from.
+func ReadSeries(ctx context.Context, rdb *redis.Client, source string, municipalities []string, from, to time.Time, resolution time.Duration) ([]SeriesPoint, error) {
+	resolution = resolution.Truncate(Resolution)
+	if resolution < Resolution {
+		resolution = Resolution
+	}
+	width := seriesBucket(resolution)
+	if seriesBuckets(from, to, width) > MaxSeriesBuckets {
+		return nil, ErrSeriesTooLong
+	}
+
+	pipe := rdb.Pipeline()
+	var starts []time.Time
+	var cmds [][]*redis.StringStringMapCmd
+	for t := from.Truncate(width); !t.After(to); t = t.Add(width) {
+		starts = append(starts, t)
+		bucket := make([]*redis.StringStringMapCmd, len(municipalities))
+		for i, municipality := range municipalities {
+			bucket[i] = pipe.HGetAll(ctx, SeriesBucketKey(source, municipality, width, t))
+		}
+		cmds = append(cmds, bucket)
+	}
+	if len(starts) == 0 {
+		return nil, nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var points []SeriesPoint
+	for i, bucket := range cmds {
+		start := starts[i].Truncate(resolution)
+		if len(points) == 0 || !points[len(points)-1].Start.Equal(start) {
+			points = append(points, SeriesPoint{Start: start})
+		}
+		p := &points[len(points)-1]
+		for _, cmd := range bucket {
+			if fields := cmd.Val(); len(fields) > 0 {
+				p.Merge(parseMunicipalityStats(fields))
+			}
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Pipeline events counted per broker and minute.
+const (
+	// EventProduced is counted by the gRPC server once the broker accepted
+	// the message.
+	EventProduced = "produced"
+	// EventPublishFailed is counted by the gRPC server when publishing fails.
+	EventPublishFailed = "publish_failed"
+	// EventConsumed is counted by Store.Record in the same step that stores
+	// the tweet.
+	EventConsumed = "consumed"
+	// EventRepaired is counted by Store.Record instead of EventConsumed for
+	// tweets a reconciliation repair copied from the other broker.
+	EventRepaired = "repaired"
+	// EventFailed is counted by a consumer once per message it gives up on,
+	// after its retries and redeliveries, whether or not it then reaches the
+	// dead-letter queue. Attempts that are retried aren't counted.
+	EventFailed = "failed"
+	// EventDeadLettered is counted by a consumer when it gives up on a
+	// message and moves it to the dead-letter queue.
+	EventDeadLettered = "dead_lettered"
+)
+
+// Events lists every counted event.
+var Events = []string{EventProduced, EventPublishFailed, EventConsumed, EventRepaired, EventFailed, EventDeadLettered}
+
+// StatsKey is the hash counting the events of source during the minute that
+// contains t, one field per event.
+func StatsKey(source string, t time.Time) string {
+	return "stats:" + source + ":" + strconv.FormatInt(t.Truncate(Resolution).Unix(), 10)
+}
+
+// StatsBucketKey is the same hash for the bucket of width, one of
+// SeriesBuckets, that contains t.
+func StatsBucketKey(source string, width time.Duration, t time.Time) string {
+	name := bucketName(width)
+	if name == "" {
+		return StatsKey(source, t)
+	}
+	return "stats:" + name + source + ":" + strconv.FormatInt(t.Truncate(width).Unix(), 10)
+}
+
+// Count counts one event for the Store's broker in the current minute, hour
+// and day. The stats are best effort, so failures are only logged.
+func (s *Store) Count(ctx context.Context, event string) {
+	now := time.Now()
+	pipe := s.rdb.Pipeline()
+	for _, width := range SeriesBuckets {
+		key := StatsBucketKey(s.source, width, now)
+		pipe.HIncrBy(ctx, key, event, 1)
+		pipe.Expire(ctx, key, s.latencyRetention)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to count %s event for %s: %v", event, s.source, err)
+	}
+}
+
+// StatsPoint holds the events of one time bucket.
+type StatsPoint struct {
+	Start  time.Time
+	Events map[string]int64
+}
+
+func parseEvents(fields map[string]string) map[string]int64 {
+	events := make(map[string]int64, len(fields))
+	for event, value := range fields {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			events[event] = n
+		}
+	}
+	return events
+}
+
+// ReadStats returns the per-minute events of source between from and to,
+// oldest first, skipping minutes without any.
+func ReadStats(ctx context.Context, rdb *redis.Client, source string, from, to time.Time) ([]StatsPoint, error) {
+	pipe := rdb.Pipeline()
+	var starts []time.Time
+	var cmds []*redis.StringStringMapCmd
+	for t := from.Truncate(Resolution); !t.After(to); t = t.Add(Resolution) {
+		starts = append(starts, t)
+		cmds = append(cmds, pipe.HGetAll(ctx, StatsKey(source, t)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var stats []StatsPoint
+	for i, cmd := range cmds {
+		if fields := cmd.Val(); len(fields) > 0 {
+			stats = append(stats, StatsPoint{Start: starts[i], Events: parseEvents(fields)})
+		}
+	}
+	return stats, nil
+}
+
+// ReadStatsSeries returns the events of source between from and to, oldest
+// first, in buckets as in ReadSeries. Buckets without events are skipped.
+func ReadStatsSeries(ctx context.Context, rdb *redis.Client, source string, from, to time.Time, resolution time.Duration) ([]StatsPoint, error) {
+	resolution = resolution.Truncate(Resolution)
+	if resolution < Resolution {
+		resolution = Resolution
+	}
+	width := seriesBucket(resolution)
+	starts, hashes, err := readBuckets(ctx, rdb, from, to, width, func(t time.Time) string {
+		return StatsBucketKey(source, width, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []StatsPoint
+	for i, fields := range hashes {
+		if len(fields) == 0 {
+			continue
+		}
+		start := starts[i].Truncate(resolution)
+		if len(stats) == 0 || !stats[len(stats)-1].Start.Equal(start) {
+			stats = append(stats, StatsPoint{Start: start, Events: make(map[string]int64)})
+		}
+		for event, n := range parseEvents(fields) {
+			stats[len(stats)-1].Events[event] += n
+		}
+	}
+	return stats, nil
+}
+
+// ReadCounters returns the lifetime per-condition counters of source.
+func ReadCounters(ctx context.Context, rdb *redis.Client, source string) (map[string]int64, error) {
+	keys := make([]string, len(Conditions))
+	for i, condition := range Conditions {
+		keys[i] = CounterKey(source, condition)
+	}
+	values, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	counters := make(map[string]int64, len(Conditions))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			counters[Conditions[i]] = n
+		}
+	}
+	return counters, nil
+}
@@ -0,0 +1,511 @@
+// Package storage owns the layout of the weather counters in Valkey.
+//
+// Every tweet is published to both Kafka and RabbitMQ, so each consumer
+// counts under its own prefix (kafka:weather:<condition>,
+// rabbitmq:weather:<condition>). The combined view, without prefix
+// (weather:<condition>), counts each tweet ID once, whichever consumer stores
+// it first. Each view has:
+//
+//	<prefix>weather:<condition>                  counter per condition
+//	<prefix>municipality:<municipality>          hash: count, temperature_sum,
+//	                                             humidity_sum, weather:<condition>
+//	<prefix>series:<municipality>:<unix minute>  the same hash per minute
+//	<prefix>series:hour:<municipality>:<unix>    the same hash per hour
+//	<prefix>series:day:<municipality>:<unix>     the same hash per UTC day
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Sources writing to Valkey.
+const (
+	SourceKafka    = "kafka"
+	SourceRabbitMQ = "rabbitmq"
+	// SourceCombined is the deduplicated view across both brokers.
+	SourceCombined = "combined"
+)
+
+// Conditions lists every weather condition the consumers count.
+var Conditions = []string{"sunny", "cloudy", "rainy", "foggy", "unknown"}
+
+// Municipalities lists every municipality the consumers count.
+var Municipalities = []string{"mixco", "guatemala", "amatitlan", "chinautla", "unknown"}
+
+// Fields of the municipality and series hashes besides weather:<condition>.
+const (
+	FieldCount          = "count"
+	FieldTemperatureSum = "temperature_sum"
+	FieldHumiditySum    = "humidity_sum"
+)
+
+const (
+	defaultSeenTTL          = 24 * time.Hour
+	defaultSeriesRetention  = 30 * 24 * time.Hour
+	migrationKey            = "migrations:per-broker-counters"
+	maxTweetsPerRecordBatch = 100
+)
+
+func prefix(source string) string {
+	if source == SourceCombined {
+		return ""
+	}
+	return source + ":"
+}
+
+// CounterKey is the counter of condition in the view of source.
+func CounterKey(source, condition string) string {
+	return prefix(source) + "weather:" + condition
+}
+
+// CombinedKey is the deduplicated counter of condition across brokers.
+func CombinedKey(condition string) string {
+	return CounterKey(SourceCombined, condition)
+}
+
+// MunicipalityKey is the lifetime stats hash of municipality in the view of
+// source.
+func MunicipalityKey(source, municipality string) string {
+	return prefix(source) + "municipality:" + municipality
+}
+
+// SeriesKey is the stats hash of municipality in the view of source for the
+// minute containing t.
+func SeriesKey(source, municipality string, t time.Time) string {
+	return prefix(source) + "series:" + municipality + ":" + strconv.FormatInt(t.Truncate(Resolution).Unix(), 10)
+}
+
+// SeriesBucketKey is the stats hash of municipality in the view of source for
+// the bucket of width, one of SeriesBuckets, that contains t.
+func SeriesBucketKey(source, municipality string, width time.Duration, t time.Time) string {
+	name := bucketName(width)
+	if name == "" {
+		return SeriesKey(source, municipality, t)
+	}
+	return prefix(source) + "series:" + name + municipality + ":" + strconv.FormatInt(t.Truncate(width).Unix(), 10)
+}
+
+// bucketName names the hour and day buckets in their keys, empty for
+// minutes.
+func bucketName(width time.Duration) string {
+	switch width {
+	case time.Hour:
+		return "hour:"
+	case 24 * time.Hour:
+		return "day:"
+	default:
+		return ""
+	}
+}
+
+// ConditionField is the field counting condition in municipality and series
+// hashes.
+func ConditionField(condition string) string {
+	return "weather:" + condition
+}
+
+// LegacyKey keeps the value a combined key had before the per-broker
+// migration, when both consumers incremented it.
+func LegacyKey(condition string) string {
+	return "legacy:weather:" + condition
+}
+
+// SeenKey marks a tweet ID as counted in the combined view.
+func SeenKey(id string) string {
+	return "seen:" + id
+}
+
+// ProcessedKey marks a tweet ID as already stored by source, so redeliveries
+// don't count it again.
+func ProcessedKey(source, id string) string {
+	return "processed:" + source + ":" + id
+}
+
+// Store records tweets for one broker.
+type Store struct {
+	rdb              *redis.Client
+	source           string
+	combined         bool
+	seenTTL          time.Duration
+	dedupeTTL        time.Duration
+	latencyRetention time.Duration
+	seriesRetention  time.Duration
+	liveStreamLength int
+	recentLength     int
+	recentRetention  time.Duration
+	observeLatency   func(stage string, d time.Duration)
+}
+
+// New returns a Store writing counters for source. The combined view is on
+// unless COMBINED_VIEW is "false"; COMBINED_SEEN_TTL sets how long tweet IDs
+// are remembered for the combined view, DEDUPE_TTL how long they are
+// remembered to ignore redeliveries, LATENCY_RETENTION how long latency
+// histograms and event stats are kept, SERIES_RETENTION how long the
+// per-minute series are kept, LIVE_STREAM_LENGTH roughly how many tweets
+// the live stream keeps and RECENT_TWEETS_LENGTH and RECENT_TWEETS_RETENTION
+// roughly how many tweets, and for how long, each recent tweets stream keeps.
+func New(rdb *redis.Client, source string) *Store {
+	return &Store{
+		rdb:              rdb,
+		source:           source,
+		combined:         os.Getenv("COMBINED_VIEW") != "false",
+		seenTTL:          getEnvDuration("COMBINED_SEEN_TTL", defaultSeenTTL),
+		dedupeTTL:        getEnvDuration("DEDUPE_TTL", defaultSeenTTL),
+		latencyRetention: getEnvDuration("LATENCY_RETENTION", defaultLatencyRetention),
+		seriesRetention:  getEnvDuration("SERIES_RETENTION", defaultSeriesRetention),
+		liveStreamLength: GetEnvInt("LIVE_STREAM_LENGTH", defaultLiveStreamLength),
+		recentLength:     GetEnvInt("RECENT_TWEETS_LENGTH", defaultRecentLength),
+		recentRetention:  getEnvDuration("RECENT_TWEETS_RETENTION", defaultRecentRetention),
+	}
+}
+
+// Load uploads the storage scripts so the first tweets already go through
+// EVALSHA. Scripts are also reloaded on demand if Valkey restarts and loses
+// them.
+func (s *Store) Load(ctx context.Context) error {
+	return recordScript.Load(ctx, s.rdb).Err()
+}
+
+// Init migrates the counters and loads the scripts, retrying with backoff
+// until both succeed or ctx is done. Consumers must not store anything before
+// it returns: tweets written ahead of the migration would be halved by it
+// once it finally runs.
+func (s *Store) Init(ctx context.Context) error {
+	backoff := 500 * time.Millisecond
+	for {
+		err := Migrate(ctx, s.rdb)
+		if err == nil {
+			err = s.Load(ctx)
+		}
+		if err == nil {
+			return nil
+		}
+		log.Printf("Failed to prepare Valkey, retrying in %v: %v", backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// Retry runs fn up to maxRetries times with exponential backoff, from 100ms
+// up to 5s, and returns how many attempts it took. Consumers wrap Record in
+// it to ride out short Valkey outages.
+func Retry(maxRetries int, fn func() error) (int, error) {
+	backoff := 100 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return attempt, nil
+		}
+		if attempt >= maxRetries {
+			return attempt, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		log.Printf("Attempt %d failed, retrying in %v: %v", attempt, backoff, err)
+		time.Sleep(backoff)
+		if backoff < 5*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func getEnvDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid value for %s (%q), using %v", name, value, def)
+		return def
+	}
+	return d
+}
+
+// GetEnvInt reads an integer setting from the environment, falling back to
+// def when it is unset or invalid.
+func GetEnvInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value for %s (%q), using %d", name, value, def)
+		return def
+	}
+	return n
+}
+
+// Tweet is what a consumer stores for every message.
+type Tweet struct {
+	ID           string
+	Municipality string
+	Condition    string
+	Temperature  int32
+	Humidity     int32
+	// ReceivedAt is when the gRPC server received the tweet. It picks the
+	// reconciliation bucket, so both brokers file a tweet under the same
+	// minute.
+	ReceivedAt time.Time
+	// Repaired marks a tweet added by a reconciliation repair, from what
+	// the other broker stored, instead of consumed from the broker.
+	Repaired bool
+}
+
+// recordScript stores a batch of tweets atomically: either every counter,
+// hash and marker of every tweet is updated or none is. The processed marker
+// is set in the same step as the counters, so a redelivered message, or a
+// retry after a timeout that did reach Valkey, is recognised and only noted
+// as a duplicate. Every stored tweet is announced on UpdatesChannel and
+// appended to the recent tweets of its municipality and, once across brokers,
+// to LiveStreamKey. Repaired tweets only update the aggregates: they are
+// counted as repaired instead of consumed and are neither announced nor added
+// to the recent tweets, since the broker never delivered them.
+//
+// ARGV starts with the settings shared by the batch, followed by
+// recordArgsPerTweet values per tweet; KEYS holds recordKeysPerTweet keys
+// per tweet. Both are documented in recordKeys and recordArgs. Tweets
+// without an ID skip deduplication, reconciliation and the combined view.
+// It returns, per tweet, 1 if it was stored and 0 if it was a duplicate.
+var recordScript = redis.NewScript(`
+local dedupe_ttl, reconcile_ttl, series_ttl, stats_ttl = ARGV[1], ARGV[2], ARGV[3], ARGV[4]
+local combined, source, seen_ttl, channel = ARGV[5] == '1', ARGV[6], ARGV[7], ARGV[8]
+local live_length, recent_length, recent_min_id = ARGV[9], ARGV[10], ARGV[11]
+local header, nkeys, nargs = 11, 19, 9
+
+local function add(hash, condition, temperature, humidity)
+  redis.call('HINCRBY', hash, 'count', 1)
+  redis.call('HINCRBY', hash, 'temperature_sum', temperature)
+  redis.call('HINCRBY', hash, 'humidity_sum', humidity)
+  redis.call('HINCRBY', hash, 'weather:' .. condition, 1)
+end
+
+local results = {}
+for i = 0, #KEYS / nkeys - 1 do
+  local k, a = i * nkeys, header + i * nargs
+  local id, condition, temperature, humidity, record = ARGV[a + 1], ARGV[a + 2], ARGV[a + 3], ARGV[a + 4], ARGV[a + 5]
+  local has_id, municipality, received_at = ARGV[a + 6] == '1', ARGV[a + 7], ARGV[a + 8]
+  local repaired = ARGV[a + 9] == '1'
+
+  if has_id and not redis.call('SET', KEYS[k + 1], '1', 'NX', 'EX', dedupe_ttl) then
+    redis.call('HINCRBY', KEYS[k + 6], id, 1)
+    redis.call('EXPIRE', KEYS[k + 6], reconcile_ttl)
+    results[#results + 1] = 0
+  else
+    redis.call('INCR', KEYS[k + 2])
+    add(KEYS[k + 3], condition, temperature, humidity)
+    for _, series in ipairs({k + 4, k + 14, k + 15}) do
+      add(KEYS[series], condition, temperature, humidity)
+      redis.call('EXPIRE', KEYS[series], series_ttl)
+    end
+    local event = repaired and 'repaired' or 'consumed'
+    for _, stats in ipairs({k + 7, k + 18, k + 19}) do
+      redis.call('HINCRBY', KEYS[stats], event, 1)
+      redis.call('EXPIRE', KEYS[stats], stats_ttl)
+    end
+    if not repaired then
+      redis.call('PUBLISH', channel, source .. ':' .. municipality .. ':' .. condition)
+      redis.call('XADD', KEYS[k + 13], 'MAXLEN', '~', recent_length, '*',
+        'id', id, 'source', source, 'condition', condition,
+        'temperature', temperature, 'humidity', humidity, 'received_at', received_at)
+      redis.call('XTRIM', KEYS[k + 13], 'MINID', '~', recent_min_id)
+    end
+    -- The first broker to store a tweet adds it to the combined view and
+    -- the live stream.
+    local first = not has_id
+    if has_id then
+      redis.call('HSET', KEYS[k + 5], id, record)
+      redis.call('EXPIRE', KEYS[k + 5], reconcile_ttl)
+      first = redis.call('SET', KEYS[k + 8], source, 'NX', 'EX', seen_ttl)
+      if combined and first then
+        redis.call('INCR', KEYS[k + 9])
+        add(KEYS[k + 10], condition, temperature, humidity)
+        for _, series in ipairs({k + 11, k + 16, k + 17}) do
+          add(KEYS[series], condition, temperature, humidity)
+          redis.call('EXPIRE', KEYS[series], series_ttl)
+        end
+        redis.call('PUBLISH', channel, 'combined:' .. municipality .. ':' .. condition)
+      end
+    end
+    if first then
+      redis.call('XADD', KEYS[k + 12], 'MAXLEN', '~', live_length, '*',
+        'id', id, 'source', source, 'municipality', municipality, 'condition', condition,
+        'temperature', temperature, 'humidity', humidity, 'received_at', received_at)
+    end
+    results[#results + 1] = 1
+  end
+end
+return results
+`)
+
+// recordKeys returns the keys recordScript touches for t, in order.
+func (s *Store) recordKeys(t Tweet, receivedAt, now time.Time) []string {
+	return []string{
+		ProcessedKey(s.source, t.ID),
+		CounterKey(s.source, t.Condition),
+		MunicipalityKey(s.source, t.Municipality),
+		SeriesKey(s.source, t.Municipality, receivedAt),
+		TweetsKey(s.source, receivedAt),
+		DuplicatesKey(s.source, receivedAt),
+		StatsKey(s.source, now),
+		SeenKey(t.ID),
+		CombinedKey(t.Condition),
+		MunicipalityKey(SourceCombined, t.Municipality),
+		SeriesKey(SourceCombined, t.Municipality, receivedAt),
+		LiveStreamKey,
+		RecentKey(t.Municipality),
+		SeriesBucketKey(s.source, t.Municipality, time.Hour, receivedAt),
+		SeriesBucketKey(s.source, t.Municipality, 24*time.Hour, receivedAt),
+		SeriesBucketKey(SourceCombined, t.Municipality, time.Hour, receivedAt),
+		SeriesBucketKey(SourceCombined, t.Municipality, 24*time.Hour, receivedAt),
+		StatsBucketKey(s.source, time.Hour, now),
+		StatsBucketKey(s.source, 24*time.Hour, now),
+	}
+}
+
+// recordArgs returns the per-tweet arguments of recordScript, in order.
+func recordArgs(t Tweet, receivedAt, now time.Time) []interface{} {
+	hasID, repaired := "0", "0"
+	if t.ID != "" {
+		hasID = "1"
+	}
+	if t.Repaired {
+		repaired = "1"
+	}
+	return []interface{}{
+		t.ID,
+		t.Condition,
+		t.Temperature,
+		t.Humidity,
+		tweetRecordValue(t, now.Sub(receivedAt)),
+		hasID,
+		t.Municipality,
+		receivedAt.UnixMilli(),
+		repaired,
+	}
+}
+
+// Record stores one tweet. It reports false, without counting anything, when
+// this broker already stored the tweet. Tweets without an ID can't be
+// deduplicated and are always counted.
+func (s *Store) Record(ctx context.Context, t Tweet) (bool, error) {
+	stored, err := s.RecordBatch(ctx, []Tweet{t})
+	if err != nil {
+		return false, err
+	}
+	return stored[0], nil
+}
+
+// RecordBatch stores tweets atomically, in chunks of at most 100 tweets, and
+// reports for each one whether it was stored or ignored as a duplicate.
+func (s *Store) RecordBatch(ctx context.Context, tweets []Tweet) ([]bool, error) {
+	stored := make([]bool, 0, len(tweets))
+	for len(tweets) > 0 {
+		n := len(tweets)
+		if n > maxTweetsPerRecordBatch {
+			n = maxTweetsPerRecordBatch
+		}
+		chunk, err := s.recordChunk(ctx, tweets[:n])
+		if err != nil {
+			return stored, err
+		}
+		stored = append(stored, chunk...)
+		tweets = tweets[n:]
+	}
+	return stored, nil
+}
+
+func (s *Store) recordChunk(ctx context.Context, tweets []Tweet) ([]bool, error) {
+	combined := "0"
+	if s.combined {
+		combined = "1"
+	}
+	now := time.Now()
+	args := []interface{}{
+		seconds(s.dedupeTTL),
+		seconds(s.seenTTL),
+		seconds(s.seriesRetention),
+		seconds(s.latencyRetention),
+		combined,
+		s.source,
+		seconds(s.seenTTL),
+		UpdatesChannel,
+		s.liveStreamLength,
+		s.recentLength,
+		now.Add(-s.recentRetention).UnixMilli(),
+	}
+	var keys []string
+	for _, t := range tweets {
+		receivedAt := t.ReceivedAt
+		if receivedAt.IsZero() {
+			receivedAt = now
+		}
+		keys = append(keys, s.recordKeys(t, receivedAt, now)...)
+		args = append(args, recordArgs(t, receivedAt, now)...)
+	}
+
+	results, err := recordScript.Run(ctx, s.rdb, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	stored := make([]bool, len(results))
+	for i, r := range results {
+		stored[i] = r == 1
+	}
+	return stored, nil
+}
+
+func seconds(d time.Duration) int64 {
+	if d < time.Second {
+		return 1
+	}
+	return int64(d / time.Second)
+}
+
+// migrateScript moves the pre-migration counters, which both consumers
+// incremented, to legacy keys and seeds the combined view with half of each,
+// the best estimate of the real number of tweets. It only ever runs once.
+var migrateScript = redis.NewScript(`
+if redis.call('SETNX', KEYS[1], '1') == 0 then
+  return -1
+end
+local moved = 0
+for i = 2, #KEYS, 2 do
+  local old = redis.call('GET', KEYS[i])
+  if old then
+    redis.call('SET', KEYS[i + 1], old)
+    redis.call('SET', KEYS[i], math.ceil(tonumber(old) / 2))
+    moved = moved + 1
+  end
+end
+return moved
+`)
+
+// Migrate converts counters written before the per-broker layout. It is safe
+// to call from every consumer on startup.
+func Migrate(ctx context.Context, rdb *redis.Client) error {
+	keys := []string{migrationKey}
+	for _, condition := range Conditions {
+		keys = append(keys, CombinedKey(condition), LegacyKey(condition))
+	}
+	moved, err := migrateScript.Run(ctx, rdb, keys).Int()
+	if err != nil {
+		return err
+	}
+	if moved >= 0 {
+		log.Printf("Migrated %d weather counters to the per-broker layout", moved)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package storage
+
+import "strings"
+
+// UpdatesChannel is the Pub/Sub channel announcing every stored tweet, once
+// for the broker view and once more when it is counted in the combined view.
+// Messages are "<source>:<municipality>:<condition>".
+const UpdatesChannel = "weather-updates"
+
+// Update is a tweet announced on UpdatesChannel.
+type Update struct {
+	Source       string
+	Municipality string
+	Condition    string
+}
+
+// ParseUpdate decodes a message published on UpdatesChannel.
+func ParseUpdate(payload string) (Update, bool) {
+	parts := strings.Split(payload, ":")
+	if len(parts) != 3 {
+		return Update{}, false
+	}
+	return Update{Source: parts[0], Municipality: parts[1], Condition: parts[2]}, true
+}
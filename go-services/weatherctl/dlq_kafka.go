@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-services/messaging"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const kafkaTimeoutMs = 10000
+
+// kafkaDLQ reads the dead-letter topic with a throwaway assignment so it never
+// commits offsets or joins the consumers' group.
+type kafkaDLQ struct {
+	consumer *kafka.Consumer
+	producer *kafka.Producer
+	fetched  map[int32][]kafka.Offset
+	records  map[string]*kafka.Message
+}
+
+func newKafkaDLQ(broker string) (*kafkaDLQ, error) {
+	c, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  broker,
+		"group.id":           "weatherctl",
+		"enable.auto.commit": false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating Kafka consumer: %w", err)
+	}
+	p, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers": broker,
+		"acks":              "all",
+	})
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("creating Kafka producer: %w", err)
+	}
+	return &kafkaDLQ{
+		consumer: c,
+		producer: p,
+		fetched:  make(map[int32][]kafka.Offset),
+		records:  make(map[string]*kafka.Message),
+	}, nil
+}
+
+func (k *kafkaDLQ) Fetch() ([]dlqMessage, error) {
+	topic := messaging.DeadLetterTopic
+	md, err := k.consumer.GetMetadata(&topic, false, kafkaTimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignment []kafka.TopicPartition
+	ends := make(map[int32]int64)
+	for _, p := range md.Topics[topic].Partitions {
+		low, high, err := k.consumer.QueryWatermarkOffsets(topic, p.ID, kafkaTimeoutMs)
+		if err != nil {
+			return nil, err
+		}
+		if high > low {
+			assignment = append(assignment, kafka.TopicPartition{Topic: &topic, Partition: p.ID, Offset: kafka.Offset(low)})
+			ends[p.ID] = high
+		}
+	}
+	if len(assignment) == 0 {
+		return nil, nil
+	}
+	if err := k.consumer.Assign(assignment); err != nil {
+		return nil, err
+	}
+
+	var msgs []dlqMessage
+	for len(ends) > 0 {
+		msg, err := k.consumer.ReadMessage(kafkaTimeoutMs * time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+		tp := msg.TopicPartition
+		id := fmt.Sprintf("%d:%d", tp.Partition, tp.Offset)
+		k.records[id] = msg
+		k.fetched[tp.Partition] = append(k.fetched[tp.Partition], tp.Offset)
+		msgs = append(msgs, kafkaToDLQMessage(id, msg))
+		if int64(tp.Offset)+1 >= ends[tp.Partition] {
+			delete(ends, tp.Partition)
+		}
+	}
+	return msgs, nil
+}
+
+func kafkaToDLQMessage(id string, msg *kafka.Message) dlqMessage {
+	headers := make(map[string]string)
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	failedAt, _ := time.Parse(time.RFC3339, headers[messaging.HeaderFailedAt])
+	env, err := messaging.DecodeKafka(msg)
+	return dlqMessage{
+		ID:       id,
+		Reason:   headers[messaging.HeaderFailureReason],
+		Error:    headers[messaging.HeaderFailureError],
+		Attempts: headers[messaging.HeaderFailureAttempts],
+		FailedAt: failedAt,
+		Source: fmt.Sprintf("%s[%s]@%s", headers[messaging.HeaderOriginalTopic],
+			headers[messaging.HeaderOriginalPartition], headers[messaging.HeaderOriginalOffset]),
+		Body:      msg.Value,
+		Envelope:  env,
+		DecodeErr: err,
+	}
+}
+
+// Replay produces msgs back to weather-tweets. Kafka can't delete single
+// records, so they stay in the dead-letter topic until purged.
+func (k *kafkaDLQ) Replay(msgs []dlqMessage) error {
+	topic := messaging.TweetsTopic
+	delivery := make(chan kafka.Event, len(msgs))
+	for _, m := range msgs {
+		orig := k.records[m.ID]
+		var headers []kafka.Header
+		for _, h := range orig.Headers {
+			if !isFailureHeader(h.Key) {
+				headers = append(headers, h)
+			}
+		}
+		err := k.producer.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+			Key:            orig.Key,
+			Value:          orig.Value,
+			Headers:        headers,
+		}, delivery)
+		if err != nil {
+			return fmt.Errorf("replaying %s: %w", m.ID, err)
+		}
+	}
+	for range msgs {
+		e := <-delivery
+		if m, ok := e.(*kafka.Message); ok && m.TopicPartition.Error != nil {
+			return fmt.Errorf("replaying: %w", m.TopicPartition.Error)
+		}
+	}
+	fmt.Println("Replayed records stay in the dead-letter topic until purged")
+	return nil
+}
+
+// Purge deletes records from the start of each partition up to the last
+// selected one. Kafka can only truncate a partition from the beginning, so
+// the selection must not leave gaps.
+func (k *kafkaDLQ) Purge(msgs []dlqMessage) error {
+	selected := make(map[string]bool)
+	upTo := make(map[int32]kafka.Offset)
+	for _, m := range msgs {
+		selected[m.ID] = true
+		tp := k.records[m.ID].TopicPartition
+		if tp.Offset+1 > upTo[tp.Partition] {
+			upTo[tp.Partition] = tp.Offset + 1
+		}
+	}
+
+	topic := messaging.DeadLetterTopic
+	var toDelete []kafka.TopicPartition
+	for partition, end := range upTo {
+		for _, offset := range k.fetched[partition] {
+			id := fmt.Sprintf("%d:%d", partition, offset)
+			if offset < end && !selected[id] {
+				return fmt.Errorf("cannot purge up to %d:%d without also purging %s", partition, end-1, id)
+			}
+		}
+		toDelete = append(toDelete, kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: end})
+	}
+	if len(toDelete) == 0 {
+		return nil
+	}
+
+	admin, err := kafka.NewAdminClientFromConsumer(k.consumer)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaTimeoutMs*time.Millisecond)
+	defer cancel()
+	res, err := admin.DeleteRecords(ctx, toDelete)
+	if err != nil {
+		return err
+	}
+	for _, r := range res.DeleteRecordsResults {
+		if r.TopicPartition.Error != nil {
+			return fmt.Errorf("purging partition %d: %w", r.TopicPartition.Partition, r.TopicPartition.Error)
+		}
+	}
+	return nil
+}
+
+func (k *kafkaDLQ) Close() {
+	k.producer.Flush(kafkaTimeoutMs)
+	k.producer.Close()
+	k.consumer.Close()
+}
+
+// isFailureHeader reports whether a header was added when dead-lettering and
+// must be dropped before the message goes back to weather-tweets.
+func isFailureHeader(key string) bool {
+	return strings.HasPrefix(key, "x-failure-") || strings.HasPrefix(key, "x-original-") ||
+		key == messaging.HeaderFailedAt || key == "x-death" || key == "x-delivery-count"
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-services/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+// rabbitMQDLQ browses the dead-letter queue by getting every message without
+// acking it. Messages that are neither replayed nor purged go back to the
+// queue, in order, when the channel is closed.
+type rabbitMQDLQ struct {
+	conn       *amqp.Connection
+	ch         *confirmChannel
+	deliveries map[string]amqp.Delivery
+}
+
+func newRabbitMQDLQ(url string) (*rabbitMQDLQ, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to RabbitMQ: %w", err)
+	}
+	// Replayed messages are only acked in the dead-letter queue once the
+	// broker confirmed it has them.
+	ch, err := openConfirmChannel(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := messaging.DeclareRabbitMQ(ch.Channel); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("declaring queues: %w", err)
+	}
+	return &rabbitMQDLQ{conn: conn, ch: ch, deliveries: make(map[string]amqp.Delivery)}, nil
+}
+
+func (r *rabbitMQDLQ) Fetch() ([]dlqMessage, error) {
+	var msgs []dlqMessage
+	for i := 1; ; i++ {
+		d, ok, err := r.ch.Get(messaging.DeadLetterQueue, false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return msgs, nil
+		}
+		id := strconv.Itoa(i)
+		r.deliveries[id] = d
+		msgs = append(msgs, rabbitMQToDLQMessage(id, d))
+	}
+}
+
+func rabbitMQToDLQMessage(id string, d amqp.Delivery) dlqMessage {
+	m := dlqMessage{
+		ID:       id,
+		Reason:   headerString(d.Headers, messaging.HeaderFailureReason),
+		Error:    headerString(d.Headers, messaging.HeaderFailureError),
+		Attempts: headerString(d.Headers, messaging.HeaderFailureAttempts),
+		Source:   headerString(d.Headers, messaging.HeaderOriginalQueue),
+		Body:     d.Body,
+	}
+	m.Envelope, m.DecodeErr = messaging.DecodeAMQP(d)
+	m.FailedAt, _ = time.Parse(time.RFC3339, headerString(d.Headers, messaging.HeaderFailedAt))
+
+	// Messages rejected by the broker itself only carry the x-death history.
+	if deaths, ok := d.Headers["x-death"].([]interface{}); ok && len(deaths) > 0 && m.Reason == "" {
+		if death, ok := deaths[0].(amqp.Table); ok {
+			m.Reason = headerString(death, "reason")
+			m.Source = headerString(death, "queue")
+			m.Attempts = headerString(death, "count")
+			if t, ok := death["time"].(time.Time); ok {
+				m.FailedAt = t
+			}
+		}
+	}
+	return m
+}
+
+func headerString(headers amqp.Table, key string) string {
+	v, ok := headers[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// Replay republishes msgs to weather-tweets and acks each in the dead-letter
+// queue once the broker confirmed the publish. A message the broker refuses
+// is requeued in the dead-letter queue.
+func (r *rabbitMQDLQ) Replay(msgs []dlqMessage) error {
+	for _, m := range msgs {
+		d := r.deliveries[m.ID]
+		headers := amqp.Table{}
+		for k, v := range d.Headers {
+			if !isFailureHeader(k) {
+				headers[k] = v
+			}
+		}
+		err := r.ch.Publish(
+			"",                    // exchange
+			messaging.TweetsQueue, // routing key
+			false,                 // mandatory
+			false,                 // immediate
+			amqp.Publishing{
+				Headers:      headers,
+				ContentType:  d.ContentType,
+				DeliveryMode: amqp.Persistent,
+				MessageId:    d.MessageId,
+				Timestamp:    d.Timestamp,
+				Body:         d.Body,
+			})
+		if err != nil {
+			return fmt.Errorf("replaying %s: %w", m.ID, err)
+		}
+		if confirm := <-r.ch.confirms; !confirm.Ack {
+			if err := d.Nack(false, true); err != nil {
+				return fmt.Errorf("requeueing %s: %w", m.ID, err)
+			}
+			return fmt.Errorf("replaying %s: broker refused the message", m.ID)
+		}
+		if err := d.Ack(false); err != nil {
+			return fmt.Errorf("acking %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// Purge acks msgs so the broker drops them.
+func (r *rabbitMQDLQ) Purge(msgs []dlqMessage) error {
+	for _, m := range msgs {
+		if err := r.deliveries[m.ID].Ack(false); err != nil {
+			return fmt.Errorf("purging %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r *rabbitMQDLQ) Close() {
+	r.ch.Close()
+	r.conn.Close()
+}
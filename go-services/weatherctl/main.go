@@ -0,0 +1,64 @@
+// Command weatherctl is an operator tool for the weather tweets pipeline.
+//
+// Usage:
+//
+//	weatherctl dlq <list|show|replay|purge> [flags]
+//	weatherctl report [flags]
+//	weatherctl reconcile [flags]
+//	weatherctl migrate-queue [flags]
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "dlq":
+		err = runDLQ(os.Args[2:])
+	case "report":
+		err = runReport(os.Args[2:])
+	case "reconcile":
+		err = runReconcile(os.Args[2:])
+	case "migrate-queue":
+		err = runMigrateQueue(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("weatherctl: %v", err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: weatherctl <command> [arguments]
+
+Commands:
+  dlq            inspect, replay and purge dead-lettered tweets
+  report         compare Kafka and RabbitMQ throughput, latency and losses
+  reconcile      find tweets one broker lost, duplicated or stored late
+  migrate-queue  convert an old weather-tweets queue to the quorum queue;
+                 scale the RabbitMQ consumers to zero first
+
+Run "weatherctl <command> -h" for the flags of each command.`)
+}
+
+func getEnv(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go-services/storage"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// brokerReconciliation is what one broker's pipeline got wrong compared with
+// the other one.
+type brokerReconciliation struct {
+	Broker     string   `json:"broker"`
+	Stored     int      `json:"stored"`
+	Missing    []string `json:"missing"`
+	Pending    []string `json:"pending"`
+	Duplicated []string `json:"duplicated"`
+	Late       []string `json:"late"`
+	Repaired   int      `json:"repaired"`
+}
+
+func runReconcile(args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	valkeyAddr := fs.String("valkey", getEnv("VALKEY_ADDR", "valkey:6379"), "Valkey address")
+	since := fs.String("since", "1h", "start of the window (RFC3339 or a duration like 2h)")
+	until := fs.String("until", "", "end of the window (RFC3339 or a duration like 2h), default now")
+	grace := fs.Duration("grace", 2*time.Minute, "tweets received more recently than this are pending, not missing")
+	lateAfter := fs.Duration("late", 5*time.Second, "receive-to-stored latency above which a tweet counts as late")
+	repair := fs.Bool("repair", false, "add tweets a broker lost, using what the other broker stored")
+	format := fs.String("format", "text", "output format: text or json")
+	verbose := fs.Bool("v", false, "list the IDs of every missing, redelivered and late tweet")
+	fs.Parse(args)
+
+	from, err := parseTime(*since)
+	if err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	to := time.Now()
+	if *until != "" {
+		if to, err = parseTime(*until); err != nil {
+			return fmt.Errorf("invalid -until: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: *valkeyAddr})
+	defer rdb.Close()
+
+	brokers := []string{storage.SourceKafka, storage.SourceRabbitMQ}
+	results := make(map[string]*brokerReconciliation, len(brokers))
+	missing := make(map[string][]storage.Tweet)
+	for _, b := range brokers {
+		results[b] = &brokerReconciliation{Broker: b}
+	}
+
+	settled := time.Now().Add(-*grace)
+	for minute := from.Truncate(storage.Resolution); !minute.After(to); minute = minute.Add(storage.Resolution) {
+		tweets := make(map[string]map[string]storage.TweetRecord, len(brokers))
+		for _, b := range brokers {
+			if tweets[b], err = storage.ReadTweets(ctx, rdb, b, minute); err != nil {
+				return fmt.Errorf("reading %s tweets: %w", b, err)
+			}
+		}
+
+		for i, b := range brokers {
+			other := brokers[1-i]
+			r := results[b]
+			r.Stored += len(tweets[b])
+
+			for id, record := range tweets[other] {
+				if _, ok := tweets[b][id]; ok {
+					continue
+				}
+				if minute.Add(storage.Resolution).After(settled) {
+					r.Pending = append(r.Pending, id)
+					continue
+				}
+				r.Missing = append(r.Missing, id)
+				missing[b] = append(missing[b], record.Tweet(id, minute))
+			}
+
+			for id, record := range tweets[b] {
+				if record.Latency > *lateAfter {
+					r.Late = append(r.Late, id)
+				}
+			}
+
+			dups, err := storage.ReadDuplicates(ctx, rdb, b, minute)
+			if err != nil {
+				return fmt.Errorf("reading %s duplicates: %w", b, err)
+			}
+			// Redeliveries are no longer counted twice; they are listed
+			// to show how often each broker delivers a message again.
+			for id := range dups {
+				r.Duplicated = append(r.Duplicated, id)
+			}
+		}
+	}
+
+	// Repairs go through the same script as the consumers, so every
+	// counter, hash and TTL of the broker's view is updated, and a tweet
+	// the consumer stored in the meantime is left alone.
+	if *repair {
+		for _, b := range brokers {
+			stored, err := storage.New(rdb, b).RecordBatch(ctx, missing[b])
+			if err != nil {
+				return fmt.Errorf("repairing %s: %w", b, err)
+			}
+			for _, added := range stored {
+				if added {
+					results[b].Repaired++
+				}
+			}
+		}
+	}
+
+	var out []brokerReconciliation
+	for _, b := range brokers {
+		r := results[b]
+		sort.Strings(r.Missing)
+		sort.Strings(r.Pending)
+		sort.Strings(r.Duplicated)
+		sort.Strings(r.Late)
+		out = append(out, *r)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	case "text":
+		printReconciliation(out, from, to, *verbose)
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
+
+func printReconciliation(results []brokerReconciliation, from, to time.Time, verbose bool) {
+	fmt.Printf("Window: %s to %s\n\n", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	fmt.Printf("%-10s %8s %8s %8s %11s %8s %8s\n", "BROKER", "STORED", "MISSING", "PENDING", "REDELIVERED", "LATE", "REPAIRED")
+	for _, r := range results {
+		fmt.Printf("%-10s %8d %8d %8d %11d %8d %8d\n", r.Broker, r.Stored, len(r.Missing), len(r.Pending), len(r.Duplicated), len(r.Late), r.Repaired)
+	}
+	if !verbose {
+		return
+	}
+	for _, r := range results {
+		printIDs(r.Broker+" missing", r.Missing)
+		printIDs(r.Broker+" redelivered", r.Duplicated)
+		printIDs(r.Broker+" late", r.Late)
+	}
+}
+
+func printIDs(title string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	for _, id := range ids {
+		fmt.Printf("  %s\n", id)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"go-services/latency"
+	"go-services/storage"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// brokerReport compares one broker's side of the pipeline over a window.
+type brokerReport struct {
+	Broker        string                   `json:"broker"`
+	Produced      int64                    `json:"produced"`
+	PublishFailed int64                    `json:"publish_failed"`
+	Consumed      int64                    `json:"consumed"`
+	Repaired      int64                    `json:"repaired"`
+	Failed        int64                    `json:"failed"`
+	DeadLettered  int64                    `json:"dead_lettered"`
+	Throughput    float64                  `json:"throughput_per_second"`
+	PeakPerMinute int64                    `json:"peak_per_minute"`
+	ErrorRate     float64                  `json:"error_rate"`
+	Lost          int64                    `json:"lost"`
+	LossRate      float64                  `json:"loss_rate"`
+	Latency       map[string]latencyReport `json:"latency"`
+	Totals        map[string]int64         `json:"lifetime_totals"`
+}
+
+type latencyReport struct {
+	Count int64   `json:"count"`
+	Mean  float64 `json:"mean_ms"`
+	P50   float64 `json:"p50_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+}
+
+type report struct {
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Brokers []brokerReport `json:"brokers"`
+}
+
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	valkeyAddr := fs.String("valkey", getEnv("VALKEY_ADDR", "valkey:6379"), "Valkey address")
+	since := fs.String("since", "1h", "start of the window (RFC3339 or a duration like 2h)")
+	until := fs.String("until", "", "end of the window (RFC3339 or a duration like 2h), default now")
+	format := fs.String("format", "markdown", "output format: markdown, json or csv")
+	output := fs.String("o", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	from, err := parseTime(*since)
+	if err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	to := time.Now()
+	if *until != "" {
+		if to, err = parseTime(*until); err != nil {
+			return fmt.Errorf("invalid -until: %w", err)
+		}
+	}
+	if !to.After(from) {
+		return fmt.Errorf("empty window: %v is not after %v", to, from)
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: *valkeyAddr})
+	defer rdb.Close()
+
+	r, err := buildReport(context.Background(), rdb, from, to)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "markdown", "md":
+		return writeMarkdown(w, r)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "csv":
+		return writeCSV(w, r)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
+
+func buildReport(ctx context.Context, rdb *redis.Client, from, to time.Time) (*report, error) {
+	r := &report{From: from, To: to}
+	window := to.Sub(from).Seconds()
+
+	for _, broker := range []string{storage.SourceKafka, storage.SourceRabbitMQ} {
+		b := brokerReport{Broker: broker, Latency: make(map[string]latencyReport)}
+
+		minutes, err := storage.ReadStats(ctx, rdb, broker, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s stats: %w", broker, err)
+		}
+		for _, m := range minutes {
+			b.Produced += m.Events[storage.EventProduced]
+			b.PublishFailed += m.Events[storage.EventPublishFailed]
+			b.Consumed += m.Events[storage.EventConsumed]
+			b.Repaired += m.Events[storage.EventRepaired]
+			b.Failed += m.Events[storage.EventFailed]
+			b.DeadLettered += m.Events[storage.EventDeadLettered]
+			if n := m.Events[storage.EventConsumed]; n > b.PeakPerMinute {
+				b.PeakPerMinute = n
+			}
+		}
+		b.Throughput = float64(b.Consumed) / window
+		if attempts := b.Consumed + b.Failed; attempts > 0 {
+			b.ErrorRate = float64(b.Failed) / float64(attempts)
+		}
+		// Repaired tweets were still lost by the broker, so they don't
+		// count against Lost.
+		if b.Lost = b.Produced - b.Consumed - b.DeadLettered; b.Lost < 0 {
+			// Messages produced before the window can be consumed inside it.
+			b.Lost = 0
+		}
+		if b.Produced > 0 {
+			b.LossRate = float64(b.Lost) / float64(b.Produced)
+		}
+
+		for _, stage := range latency.Stages {
+			h, err := storage.ReadLatency(ctx, rdb, broker, stage, from, to)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s %s latency: %w", broker, stage, err)
+			}
+			b.Latency[stage] = latencyReport{
+				Count: h.Count(),
+				Mean:  ms(h.Mean()),
+				P50:   ms(h.Percentile(50)),
+				P95:   ms(h.Percentile(95)),
+				P99:   ms(h.Percentile(99)),
+			}
+		}
+
+		if b.Totals, err = storage.ReadCounters(ctx, rdb, broker); err != nil {
+			return nil, fmt.Errorf("reading %s counters: %w", broker, err)
+		}
+		r.Brokers = append(r.Brokers, b)
+	}
+	return r, nil
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func writeMarkdown(w io.Writer, r *report) error {
+	fmt.Fprintf(w, "# Kafka vs RabbitMQ\n\n")
+	fmt.Fprintf(w, "Window: %s to %s\n\n", r.From.Format(time.RFC3339), r.To.Format(time.RFC3339))
+
+	fmt.Fprintf(w, "## Throughput and reliability\n\n")
+	fmt.Fprintf(w, "| Broker | Produced | Publish failed | Consumed | Failed | Dead-lettered | Msg/s | Peak/min | Error rate | Lost | Loss rate | Repaired |\n")
+	fmt.Fprintf(w, "|---|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	for _, b := range r.Brokers {
+		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %.2f | %d | %.2f%% | %d | %.2f%% | %d |\n",
+			b.Broker, b.Produced, b.PublishFailed, b.Consumed, b.Failed, b.DeadLettered,
+			b.Throughput, b.PeakPerMinute, b.ErrorRate*100, b.Lost, b.LossRate*100, b.Repaired)
+	}
+
+	fmt.Fprintf(w, "\n## Latency (ms)\n\n")
+	fmt.Fprintf(w, "| Broker | Stage | Count | Mean | p50 | p95 | p99 |\n")
+	fmt.Fprintf(w, "|---|---|---:|---:|---:|---:|---:|\n")
+	for _, b := range r.Brokers {
+		for _, stage := range latency.Stages {
+			l := b.Latency[stage]
+			fmt.Fprintf(w, "| %s | %s | %d | %.2f | %.2f | %.2f | %.2f |\n", b.Broker, stage, l.Count, l.Mean, l.P50, l.P95, l.P99)
+		}
+	}
+
+	fmt.Fprintf(w, "\n## Lifetime counts per condition\n\n")
+	fmt.Fprintf(w, "| Condition |")
+	for _, b := range r.Brokers {
+		fmt.Fprintf(w, " %s |", b.Broker)
+	}
+	fmt.Fprintf(w, "\n|---|")
+	for range r.Brokers {
+		fmt.Fprintf(w, "---:|")
+	}
+	fmt.Fprintln(w)
+	for _, condition := range storage.Conditions {
+		fmt.Fprintf(w, "| %s |", condition)
+		for _, b := range r.Brokers {
+			fmt.Fprintf(w, " %d |", b.Totals[condition])
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// writeCSV writes one row per broker and metric so the output loads straight
+// into a spreadsheet.
+func writeCSV(w io.Writer, r *report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"broker", "metric", "value"})
+	for _, b := range r.Brokers {
+		rows := [][2]string{
+			{"produced", strconv.FormatInt(b.Produced, 10)},
+			{"publish_failed", strconv.FormatInt(b.PublishFailed, 10)},
+			{"consumed", strconv.FormatInt(b.Consumed, 10)},
+			{"failed", strconv.FormatInt(b.Failed, 10)},
+			{"dead_lettered", strconv.FormatInt(b.DeadLettered, 10)},
+			{"throughput_per_second", strconv.FormatFloat(b.Throughput, 'f', 4, 64)},
+			{"peak_per_minute", strconv.FormatInt(b.PeakPerMinute, 10)},
+			{"error_rate", strconv.FormatFloat(b.ErrorRate, 'f', 6, 64)},
+			{"lost", strconv.FormatInt(b.Lost, 10)},
+			{"loss_rate", strconv.FormatFloat(b.LossRate, 'f', 6, 64)},
+			{"repaired", strconv.FormatInt(b.Repaired, 10)},
+		}
+		for _, stage := range latency.Stages {
+			l := b.Latency[stage]
+			rows = append(rows,
+				[2]string{stage + "_count", strconv.FormatInt(l.Count, 10)},
+				[2]string{stage + "_mean_ms", strconv.FormatFloat(l.Mean, 'f', 3, 64)},
+				[2]string{stage + "_p50_ms", strconv.FormatFloat(l.P50, 'f', 3, 64)},
+				[2]string{stage + "_p95_ms", strconv.FormatFloat(l.P95, 'f', 3, 64)},
+				[2]string{stage + "_p99_ms", strconv.FormatFloat(l.P99, 'f', 3, 64)},
+			)
+		}
+		for _, condition := range storage.Conditions {
+			rows = append(rows, [2]string{"total_" + condition, strconv.FormatInt(b.Totals[condition], 10)})
+		}
+		for _, row := range rows {
+			cw.Write([]string{b.Broker, row[0], row[1]})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
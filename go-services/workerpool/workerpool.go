@@ -0,0 +1,56 @@
+// Package workerpool runs consumer work in parallel while keeping the order of
+// messages that share a key.
+package workerpool
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// Pool runs jobs on a fixed set of workers. Every key is hashed to a single
+// worker, so jobs with the same key run one at a time in the order they were
+// submitted while different keys are processed in parallel.
+type Pool struct {
+	queues []chan func()
+	wg     sync.WaitGroup
+}
+
+// New starts workers goroutines, each with room for queueSize pending jobs.
+// Submit blocks once a worker's queue is full, which bounds the work in
+// flight.
+func New(workers, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	p := &Pool{queues: make([]chan func(), workers)}
+	for i := range p.queues {
+		q := make(chan func(), queueSize)
+		p.queues[i] = q
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range q {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// Submit queues job on the worker that owns key.
+func (p *Pool) Submit(key string, job func()) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	p.queues[h.Sum32()%uint32(len(p.queues))] <- job
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish.
+func (p *Pool) Close() {
+	for _, q := range p.queues {
+		close(q)
+	}
+	p.wg.Wait()
+}
@@ -0,0 +1,90 @@
+package workerpool
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSameKeyRunsInSubmitOrder(t *testing.T) {
+	p := New(4, 8)
+	var mu sync.Mutex
+	got := make(map[string][]int)
+	for i := 0; i < 200; i++ {
+		key := strconv.Itoa(i % 5)
+		p.Submit(key, func() {
+			// Jitter so an unordered pool would show it.
+			time.Sleep(time.Duration(i%3) * 100 * time.Microsecond)
+			mu.Lock()
+			got[key] = append(got[key], i)
+			mu.Unlock()
+		})
+	}
+	p.Close()
+
+	for key, seq := range got {
+		if len(seq) != 40 {
+			t.Errorf("key %s ran %d jobs, want 40", key, len(seq))
+		}
+		for j := 1; j < len(seq); j++ {
+			if seq[j] < seq[j-1] {
+				t.Errorf("key %s ran job %d after %d", key, seq[j], seq[j-1])
+			}
+		}
+	}
+}
+
+func TestSameKeyRunsOneAtATime(t *testing.T) {
+	p := New(4, 8)
+	var running, overlaps int32
+	for i := 0; i < 50; i++ {
+		p.Submit("same", func() {
+			if atomic.AddInt32(&running, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			time.Sleep(100 * time.Microsecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	p.Close()
+	if overlaps > 0 {
+		t.Errorf("%d jobs with the same key overlapped", overlaps)
+	}
+}
+
+func TestCloseDrainsQueuedJobs(t *testing.T) {
+	p := New(2, 100)
+	release := make(chan struct{})
+	var done int32
+	// Hold both workers so the rest of the jobs are still queued when Close
+	// is called.
+	for i := 0; i < 2; i++ {
+		p.Submit(strconv.Itoa(i), func() {
+			<-release
+			atomic.AddInt32(&done, 1)
+		})
+	}
+	for i := 0; i < 50; i++ {
+		p.Submit(strconv.Itoa(i), func() {
+			atomic.AddInt32(&done, 1)
+		})
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the queued jobs ran")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-closed
+	if done != 52 {
+		t.Errorf("%d jobs ran before Close returned, want 52", done)
+	}
+}